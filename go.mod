@@ -5,4 +5,11 @@ go 1.25.1
 require (
 	github.com/google/uuid v1.6.0 // direct
 	github.com/lib/pq v1.10.9 // direct
+	golang.org/x/net v0.50.0 // direct
+	golang.org/x/term v0.40.0 // direct
+)
+
+require (
+	golang.org/x/sys v0.41.0 // indirect
+	golang.org/x/text v0.34.0 // indirect
 )
@@ -0,0 +1,56 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Fraegdegjevar/Gator/internal/config"
+	"github.com/Fraegdegjevar/Gator/internal/database"
+	"github.com/google/uuid"
+)
+
+// Add a feed (name + url) to the database, owned by the current user
+// in config. The current user also follows the new feed. If url is a
+// web page rather than a feed, the feed it links to is added instead -
+// when there are several, [choice] picks one.
+func HandlerAddFeed(ctx context.Context, fs config.FileSystem, s *State, cmd Command, user database.User) error {
+	name := cmd.Args[0]
+	choice := ""
+	if len(cmd.Args) == 3 {
+		choice = cmd.Args[2]
+	}
+
+	candidate, err := discoverFeed(ctx, cmd.Args[1], choice)
+	if err != nil {
+		return err
+	}
+	url := candidate.URL
+
+	feed, err := s.Db.CreateFeed(
+		ctx,
+		database.CreateFeedParams{
+			ID:        uuid.New(),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+			Name:      name,
+			Url:       url,
+			UserID:    user.ID,
+		})
+	if err != nil {
+		return fmt.Errorf("error adding feed to database: %w", err)
+	}
+
+	_, err = followFeed(ctx, s, user, feed)
+	if err != nil {
+		return err
+	}
+	return s.Out.Result(
+		struct {
+			ID   uuid.UUID `json:"id"`
+			Name string    `json:"name"`
+			URL  string    `json:"url"`
+			User string    `json:"user"`
+		}{feed.ID, feed.Name, feed.Url, user.Name},
+		"Added %v (%v) - %v is now following it", feed.Name, feed.Url, user.Name)
+}
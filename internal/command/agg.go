@@ -0,0 +1,86 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Fraegdegjevar/Gator/internal/config"
+	"github.com/Fraegdegjevar/Gator/internal/retention"
+	"github.com/Fraegdegjevar/Gator/internal/rss"
+	"github.com/Fraegdegjevar/Gator/internal/scraper"
+)
+
+// How long a single feed download may take before we give up on it.
+const fetchTimeout = 30 * time.Second
+
+// How often agg prunes old posts when agg_auto_prune is set.
+const autoPruneInterval = time.Hour
+
+// Long-running aggregator. Every <interval> the least recently fetched
+// feeds are downloaded concurrently and their items stored as posts.
+// Worker count and per-host limits come from config, as does whether to
+// prune old posts along the way. Runs until interrupted with Ctrl-C
+// (SIGINT).
+func HandlerAgg(ctx context.Context, fs config.FileSystem, s *State, cmd Command) error {
+	interval, err := time.ParseDuration(cmd.Args[0])
+	if err != nil {
+		return fmt.Errorf("invalid interval %q: %w", cmd.Args[0], err)
+	}
+	if interval <= 0 {
+		return fmt.Errorf("interval must be positive, got %v", interval)
+	}
+
+	agg := scraper.New(s.Db, rss.NewClient(fetchTimeout), scraper.Options{
+		Workers:         s.Config.AggWorkers,
+		BatchSize:       s.Config.AggBatchSize,
+		HostConcurrency: s.Config.AggHostConcurrency,
+		HostRate:        s.Config.AggHostRate,
+		MaxFailures:     s.Config.AggMaxFailures,
+		Out:             s.Out.Log(),
+	})
+
+	var pruning sync.WaitGroup
+	if s.Config.AggAutoPrune {
+		policy := retentionPolicy(s.Config)
+		if !policy.Enabled() {
+			return fmt.Errorf("agg_auto_prune is set but %w", retention.ErrNoPolicy)
+		}
+		pruning.Add(1)
+		go func() {
+			defer pruning.Done()
+			autoPrune(ctx, s, policy)
+		}()
+	}
+
+	s.Out.Status("Collecting feeds every %v", interval)
+	err = agg.Run(ctx, interval)
+	pruning.Wait()
+	if err != nil {
+		return err
+	}
+	s.Out.Status("Stopped aggregator.")
+	return nil
+}
+
+// Prune straight away and then every autoPruneInterval until ctx is
+// cancelled. Failures are reported but don't stop the aggregator.
+func autoPrune(ctx context.Context, s *State, policy retention.Policy) {
+	ticker := time.NewTicker(autoPruneInterval)
+	defer ticker.Stop()
+	for {
+		removed, err := retention.Prune(ctx, s.Db, policy, time.Now(), false)
+		if err != nil && ctx.Err() == nil {
+			s.Out.Status("error pruning posts: %v", err)
+		} else if removed > 0 {
+			s.Out.Status("Pruned %v old posts", removed)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
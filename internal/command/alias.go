@@ -0,0 +1,168 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/Fraegdegjevar/Gator/internal/config"
+	"github.com/Fraegdegjevar/Gator/internal/shell"
+)
+
+// ErrAliasCycle is a usage error, so it matches ErrUsage as well.
+var ErrAliasCycle error = aliasCycleError{}
+
+type aliasCycleError struct{}
+
+func (aliasCycleError) Error() string { return "alias refers back to itself" }
+
+func (aliasCycleError) Unwrap() error { return ErrUsage }
+
+// An alias as alias list shows it.
+type aliasResult struct {
+	Name    string `json:"name"`
+	Command string `json:"command"`
+}
+
+// Manage aliases: short names for other commands, saved in config.
+//
+//	alias set <name> <command> [args]...
+//	alias rm <name>
+//	alias list
+//
+// Everything after the name is the command line, flags and all. A single
+// argument is taken as the whole line, so it can also be quoted:
+// alias set b 'browse --unread'. Separate commands with ";" to run
+// several in turn.
+func (c *Commands) handlerAlias(ctx context.Context, fs config.FileSystem, s *State, cmd Command) error {
+	switch cmd.Args[0] {
+	case "set":
+		if len(cmd.Args) < 3 {
+			return cmd.Usage("alias set needs a name and a command")
+		}
+		return c.setAlias(fs, s, cmd.Args[1], cmd.Args[2:])
+	case "rm":
+		if len(cmd.Args) != 2 {
+			return cmd.Usage("alias rm needs the name of one alias")
+		}
+		err := s.Config.RemoveAlias(fs, cmd.Args[1])
+		if err != nil {
+			return err
+		}
+		return s.Out.Result(
+			struct {
+				Name string `json:"name"`
+			}{cmd.Args[1]},
+			"Removed alias %v", cmd.Args[1])
+	case "list":
+		if len(cmd.Args) != 1 {
+			return cmd.Usage("alias list takes no arguments")
+		}
+		results := []aliasResult{}
+		for _, name := range slices.Sorted(maps.Keys(s.Config.Aliases)) {
+			results = append(results, aliasResult{name, s.Config.Aliases[name]})
+		}
+		return s.Out.List(results, "No aliases yet - add one with alias set <name> <command>.")
+	default:
+		return cmd.Usage("unknown alias command %q - use set, rm or list", cmd.Args[0])
+	}
+}
+
+func (c *Commands) setAlias(fs config.FileSystem, s *State, name string, words []string) error {
+	if name == "" || strings.HasPrefix(name, "-") || strings.ContainsAny(name, " \t;") {
+		return fmt.Errorf("invalid alias name %q", name)
+	}
+	// Aliases are only expanded for names that aren't commands, so one
+	// with a command's name would never run.
+	if _, ok := c.Registry[name]; ok {
+		return fmt.Errorf("can't alias %q - it's already a command", name)
+	}
+
+	expansion := words[0]
+	if len(words) > 1 {
+		quoted := []string{}
+		for _, word := range words {
+			quoted = append(quoted, shell.Quote(word))
+		}
+		expansion = strings.Join(quoted, " ")
+	}
+
+	// Check the alias expands (without cycles) to commands that exist
+	// before saving it.
+	aliases := maps.Clone(s.Config.Aliases)
+	if aliases == nil {
+		aliases = make(map[string]string)
+	}
+	aliases[name] = expansion
+	cmds, err := c.expandAlias(aliases, Command{Name: name}, nil)
+	if err != nil {
+		return err
+	}
+	for _, cmd := range cmds {
+		if _, ok := c.Registry[cmd.Name]; !ok {
+			return fmt.Errorf("can't alias %q: %w", name, c.notFound(cmd.Name))
+		}
+	}
+
+	err = s.Config.SetAlias(fs, name, expansion)
+	if err != nil {
+		return err
+	}
+	return s.Out.Result(aliasResult{name, expansion}, "%v is now an alias for %v", name, expansion)
+}
+
+// expandAlias turns cmd into the commands it stands for, expanding
+// aliases within aliases. Arguments given to the alias are added to the
+// end of its last command. Commands always win over aliases, so
+// expansion stops at any registered name. seen holds the aliases being
+// expanded, to catch cycles.
+func (c *Commands) expandAlias(aliases map[string]string, cmd Command, seen []string) ([]Command, error) {
+	expansion, ok := aliases[cmd.Name]
+	if _, isCommand := c.Registry[cmd.Name]; isCommand || !ok {
+		return []Command{cmd}, nil
+	}
+	if slices.Contains(seen, cmd.Name) {
+		return nil, fmt.Errorf("%w: %v", ErrAliasCycle, strings.Join(append(seen, cmd.Name), " -> "))
+	}
+	seen = append(seen, cmd.Name)
+
+	// Split on ";" first, so a ";" inside quotes isn't supported.
+	parts := strings.Split(expansion, ";")
+	cmds := []Command{}
+	for i, part := range parts {
+		words, err := shell.Split(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid alias %v: %w", cmd.Name, err)
+		}
+		if len(words) == 0 {
+			return nil, fmt.Errorf("invalid alias %v: empty command", cmd.Name)
+		}
+		next := Command{Name: words[0].Text, Args: []string{}}
+		for _, word := range words[1:] {
+			next.Args = append(next.Args, word.Text)
+		}
+		if i == len(parts)-1 {
+			next.Args = append(next.Args, cmd.Args...)
+		}
+
+		expanded, err := c.expandAlias(aliases, next, seen)
+		if err != nil {
+			return nil, err
+		}
+		cmds = append(cmds, expanded...)
+	}
+	return cmds, nil
+}
+
+// completeAliasArgs offers the alias commands, then alias names to rm.
+func completeAliasArgs(ctx context.Context, s *State, args []string) []string {
+	switch {
+	case len(args) == 0:
+		return []string{"set", "rm", "list"}
+	case len(args) == 1 && args[0] == "rm" && s.Config != nil:
+		return slices.Sorted(maps.Keys(s.Config.Aliases))
+	}
+	return nil
+}
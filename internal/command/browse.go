@@ -0,0 +1,73 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Fraegdegjevar/Gator/internal/config"
+	"github.com/Fraegdegjevar/Gator/internal/database"
+	"github.com/google/uuid"
+)
+
+// Number of posts browse shows if no limit is supplied.
+const defaultBrowseLimit = 2
+
+// A post as browse shows it.
+type postResult struct {
+	Title     string     `json:"title"`
+	Feed      string     `json:"feed"`
+	Published *time.Time `json:"published_at,omitempty" label:"Date"`
+	Unread    bool       `json:"unread"`
+	Link      string     `json:"url"`
+	ID        uuid.UUID  `json:"id"`
+}
+
+// Show the most recent posts from feeds the current user follows, newest
+// first. An optional argument overrides how many are shown, --unread
+// hides posts the user has already read and --tag only shows posts from
+// feeds with that tag.
+func HandlerBrowse(ctx context.Context, fs config.FileSystem, s *State, cmd Command, user database.User) error {
+	unreadOnly := cmd.Bool("unread")
+	tag := tagFilter(cmd)
+
+	limit := defaultBrowseLimit
+	if len(cmd.Args) == 1 {
+		n, err := strconv.Atoi(cmd.Args[0])
+		if err != nil || n < 1 {
+			return cmd.Usage("limit must be a positive whole number, got %q", cmd.Args[0])
+		}
+		limit = n
+	}
+
+	posts, err := s.Db.GetPostsForUser(
+		ctx,
+		database.GetPostsForUserParams{
+			UserID:     user.ID,
+			UnreadOnly: unreadOnly,
+			Tag:        tag,
+			MaxPosts:   int32(limit),
+		})
+	if err != nil {
+		return fmt.Errorf("error getting posts: %w", err)
+	}
+
+	results := []postResult{}
+	for _, post := range posts {
+		results = append(results, postResult{
+			Title:     post.Title,
+			Feed:      post.FeedName,
+			Published: nullTime(post.PublishedAt),
+			Unread:    !post.Read,
+			Link:      post.Url,
+			ID:        post.ID,
+		})
+	}
+
+	empty := "No posts found - follow some feeds and run agg."
+	if unreadOnly {
+		empty = "No unread posts."
+	}
+	return s.Out.List(results, empty)
+}
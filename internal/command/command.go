@@ -1,7 +1,12 @@
 package command
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
 
 	"github.com/Fraegdegjevar/Gator/internal/config"
 )
@@ -9,29 +14,303 @@ import (
 //export a command not found error for use elsewhere + for testing
 var ErrCommandNotFound = errors.New("command not found")
 
+// Matches every *UsageError, for callers that only care whether the
+// command was used wrongly.
+var ErrUsage = errors.New("usage")
+
+// A Command is one invocation. Run fills in Flags from the raw
+// arguments, leaving only positional ones in Args, before calling the
+// handler.
 type Command struct {
+	Name  string
+	Args  []string
+	Flags map[string]string
+
+	desc *Descriptor
+}
+
+type Handler func(context.Context, config.FileSystem, *State, Command) error
+
+// A Descriptor is everything the dispatcher knows about a command: the
+// handler to run, plus the text help shows and the number of arguments
+// Run accepts before calling the handler.
+type Descriptor struct {
 	Name string
-	Args []string
+	// One line shown in the command list.
+	Summary string
+	// The arguments after the command name, e.g. "<name> <url>".
+	Usage string
+	// Bounds on the number of positional arguments. MaxArgs of -1 means
+	// no upper limit.
+	MinArgs int
+	MaxArgs int
+	// After this many positional arguments the rest are passed on as
+	// they are, without looking for flags, for commands whose trailing
+	// arguments are another command line. Zero parses them all.
+	RawArgs  int
+	Flags    []Flag
+	Examples []string
+	// Streaming commands run until interrupted, so --timeout doesn't
+	// apply to them.
+	Streaming bool
+	// Commands that handle Ctrl-C themselves (the shell, which cancels
+	// just the command it's running) are only stopped by SIGTERM.
+	HandlesInterrupt bool
+	// Offers values for tab completion of positional arguments. May be
+	// nil.
+	Complete CompleteFunc
+	// Hidden commands are for gator's own use (by the completion
+	// scripts) and are left out of help, suggestions and completion.
+	Hidden bool
+	// Commands that use State.Db. Run connects to the database before
+	// calling them; other commands work without it.
+	NeedsDB bool
+	Handler Handler
+}
+
+// UsageLine is the command name followed by its flags and arguments.
+func (d Descriptor) UsageLine() string {
+	parts := []string{d.Name}
+	for _, flag := range d.Flags {
+		parts = append(parts, flag.usage())
+	}
+	if d.Usage != "" {
+		parts = append(parts, d.Usage)
+	}
+	return strings.Join(parts, " ")
+}
+
+func (d *Descriptor) flag(name string) (Flag, bool) {
+	for _, flag := range d.Flags {
+		if flag.Name == name {
+			return flag, true
+		}
+	}
+	return Flag{}, false
 }
 
 type Commands struct {
-	Registry map[string]func(config.FileSystem, *State, Command) error
+	Registry   map[string]Descriptor
+	middleware []Middleware
 }
 
-func (c *Commands) Run(fs config.FileSystem, s *State, cmd Command) error {
-	fn, ok := c.Registry[cmd.Name]
-	if !ok {
-		return ErrCommandNotFound
+// NewCommands returns a dispatcher with only the commands that drive the
+// dispatcher itself, help, shell, completion and alias, registered.
+func NewCommands() *Commands {
+	c := &Commands{
+		Registry: make(map[string]Descriptor),
 	}
+	c.Register(Descriptor{
+		Name:     "help",
+		Summary:  "List commands or show how to use one",
+		Usage:    "[command]",
+		MaxArgs:  1,
+		Examples: []string{"help", "help browse"},
+		Handler:  c.handlerHelp,
+	})
+	c.Register(Descriptor{
+		Name:             "shell",
+		Summary:          "Run commands interactively, with history and tab completion",
+		Examples:         []string{"shell"},
+		Streaming:        true,
+		HandlesInterrupt: true,
+		Handler:          c.handlerShell,
+	})
+	c.Register(Descriptor{
+		Name:     "completion",
+		Summary:  "Print a tab completion script for bash, zsh or fish",
+		Usage:    "<bash|zsh|fish>",
+		MinArgs:  1,
+		MaxArgs:  1,
+		Examples: []string{"completion bash > /etc/bash_completion.d/gator", "completion fish | source"},
+		Complete: firstArg(func(ctx context.Context, s *State) []string {
+			return completionShells
+		}),
+		Handler: c.handlerCompletion,
+	})
+	c.Register(Descriptor{
+		Name:    "alias",
+		Summary: "Add, remove or list aliases for commands",
+		Usage:   "set <name> <command> [args]... | rm <name> | list",
+		MinArgs: 1,
+		MaxArgs: -1,
+		// set <name>, then the command line.
+		RawArgs: 2,
+		Examples: []string{
+			"alias set b 'browse --unread'",
+			"alias set gonews browse --unread --tag go",
+			"alias set catchup 'tags; browse --unread 10'",
+			"alias rm b",
+			"alias list",
+		},
+		Complete: completeAliasArgs,
+		Handler:  c.handlerAlias,
+	})
+	c.Register(Descriptor{
+		Name:    "__complete",
+		Summary: "Print the completions for a partly typed command line",
+		Usage:   "-- [word]...",
+		MaxArgs: -1,
+		Hidden:  true,
+		Handler: c.handlerComplete,
+	})
+	return c
+}
 
-	err := fn(fs, s, cmd)
+// Run runs cmd, or if it names an alias from config, each command the
+// alias stands for in turn, stopping at the first error.
+func (c *Commands) Run(ctx context.Context, fs config.FileSystem, s *State, cmd Command) error {
+	if s.Config == nil {
+		return c.run(ctx, fs, s, cmd)
+	}
+	cmds, err := c.expandAlias(s.Config.Aliases, cmd, nil)
 	if err != nil {
 		return err
 	}
+	for _, cmd := range cmds {
+		err := c.run(ctx, fs, s, cmd)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HandlesInterrupt reports whether cmd, or any command it stands for if
+// it names an alias, handles Ctrl-C itself.
+func (c *Commands) HandlesInterrupt(s *State, cmd Command) bool {
+	cmds := []Command{cmd}
+	if s.Config != nil {
+		expanded, err := c.expandAlias(s.Config.Aliases, cmd, nil)
+		if err == nil {
+			cmds = expanded
+		}
+	}
+	for _, cmd := range cmds {
+		if d, ok := c.Registry[cmd.Name]; ok && d.HandlesInterrupt {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Commands) run(ctx context.Context, fs config.FileSystem, s *State, cmd Command) error {
+	d, ok := c.Registry[cmd.Name]
+	if !ok {
+		return c.notFound(cmd.Name)
+	}
+
+	if wantsHelp(cmd.Args) {
+		return showHelp(s, d)
+	}
+
+	args, flags, err := parseFlags(&d, cmd.Args)
+	if err != nil {
+		return &UsageError{Command: d, Reason: err.Error()}
+	}
+	if len(args) < d.MinArgs {
+		return &UsageError{Command: d, Reason: "missing arguments"}
+	}
+	if d.MaxArgs >= 0 && len(args) > d.MaxArgs {
+		return &UsageError{Command: d, Reason: "too many arguments"}
+	}
+	cmd.Args, cmd.Flags, cmd.desc = args, flags, &d
+
+	handler := d.Handler
+	if d.NeedsDB {
+		handler = connectDB(handler)
+	}
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		handler = c.middleware[i](handler)
+	}
+	err = handler(ctx, fs, s, cmd)
+	if err != nil {
+		return dbUnavailable(err)
+	}
 
 	return nil
 }
 
-func (c *Commands) Register(name string, f func(config.FileSystem, *State, Command) error) {
-	c.Registry[name] = f
+func (c *Commands) Register(d Descriptor) {
+	c.Registry[d.Name] = d
+}
+
+// Use adds middleware run around every command. The first middleware
+// added is the outermost.
+func (c *Commands) Use(middleware ...Middleware) {
+	c.middleware = append(c.middleware, middleware...)
+}
+
+// Names returns every registered command name, except hidden ones, in
+// alphabetical order.
+func (c *Commands) Names() []string {
+	names := []string{}
+	for _, name := range slices.Sorted(maps.Keys(c.Registry)) {
+		if !c.Registry[name].Hidden {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// wantsHelp reports whether --help appears before any "--".
+func wantsHelp(args []string) bool {
+	for _, arg := range args {
+		if arg == "--" {
+			return false
+		}
+		if arg == "--help" {
+			return true
+		}
+	}
+	return false
+}
+
+// notFound builds the error for an unknown command, suggesting the
+// closest registered name where there is one.
+func (c *Commands) notFound(name string) error {
+	if suggestion, ok := c.suggest(name); ok {
+		return fmt.Errorf("%w: %q - did you mean %q?", ErrCommandNotFound, name, suggestion)
+	}
+	return fmt.Errorf("%w: %q - run help to list commands", ErrCommandNotFound, name)
+}
+
+// suggest finds the registered name closest to a mistyped one, if any is
+// close enough to plausibly be what was meant.
+func (c *Commands) suggest(name string) (string, bool) {
+	best, bestDistance := "", -1
+	for _, candidate := range c.Names() {
+		d := editDistance(name, candidate)
+		if bestDistance == -1 || d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+	if bestDistance == -1 || bestDistance > 2 || bestDistance >= len(name) {
+		return "", false
+	}
+	return best, true
+}
+
+// editDistance is the Levenshtein distance between a and b: the fewest
+// single character insertions, deletions and substitutions turning one
+// into the other.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
 }
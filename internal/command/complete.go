@@ -0,0 +1,141 @@
+package command
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/Fraegdegjevar/Gator/internal/database"
+)
+
+// A CompleteFunc returns every value the next positional argument of a
+// command could take, given the positional arguments before it.
+type CompleteFunc func(ctx context.Context, s *State, args []string) []string
+
+// complete returns the values the word after words could take: a command
+// name for the first word, otherwise the command's flags and whatever its
+// Complete hook offers.
+func (c *Commands) complete(ctx context.Context, s *State, words []string) []string {
+	if len(words) == 0 {
+		names := c.Names()
+		if s.Config != nil {
+			names = append(names, slices.Sorted(maps.Keys(s.Config.Aliases))...)
+		}
+		return names
+	}
+	d, ok := c.Registry[words[0]]
+	if !ok {
+		return nil
+	}
+
+	// Completing the value of a flag isn't supported.
+	if last := words[len(words)-1]; len(words) > 1 && strings.HasPrefix(last, "--") && !strings.Contains(last, "=") {
+		if flag, ok := d.flag(strings.TrimPrefix(last, "--")); ok && flag.Type != BoolFlag {
+			return nil
+		}
+	}
+
+	candidates := []string{}
+	for _, flag := range d.Flags {
+		candidates = append(candidates, "--"+flag.Name)
+	}
+	// Completion carries on without database values if it's down.
+	if d.Complete != nil && (!d.NeedsDB || s.connect(ctx) == nil) {
+		args, _, err := parseFlags(&d, words[1:])
+		if err == nil {
+			candidates = append(candidates, d.Complete(ctx, s, args)...)
+		}
+	}
+	return candidates
+}
+
+// firstArg adapts a completion that only applies to the first positional
+// argument.
+func firstArg(complete func(ctx context.Context, s *State) []string) CompleteFunc {
+	return func(ctx context.Context, s *State, args []string) []string {
+		if len(args) != 0 {
+			return nil
+		}
+		return complete(ctx, s)
+	}
+}
+
+// completeUsernames offers the name of every registered user.
+func completeUsernames(ctx context.Context, s *State) []string {
+	users, err := s.Db.GetUsers(ctx)
+	if err != nil {
+		return nil
+	}
+	names := []string{}
+	for _, user := range users {
+		names = append(names, user.Name)
+	}
+	return names
+}
+
+// completeFeedURLs offers the url of every feed in the database.
+func completeFeedURLs(ctx context.Context, s *State) []string {
+	feeds, err := s.Db.GetFeeds(ctx)
+	if err != nil {
+		return nil
+	}
+	urls := []string{}
+	for _, feed := range feeds {
+		urls = append(urls, feed.Url)
+	}
+	return urls
+}
+
+// completeFollowedURLs offers the url of every feed the current user
+// follows.
+func completeFollowedURLs(ctx context.Context, s *State) []string {
+	urls := []string{}
+	for _, follow := range currentFollows(ctx, s) {
+		urls = append(urls, follow.FeedUrl)
+	}
+	return urls
+}
+
+// completeFollowedNames offers the name of every feed the current user
+// follows.
+func completeFollowedNames(ctx context.Context, s *State) []string {
+	names := []string{}
+	for _, follow := range currentFollows(ctx, s) {
+		names = append(names, follow.FeedName)
+	}
+	return names
+}
+
+// completeTagArgs offers followed feed urls for the first argument of
+// tag and untag, then the current user's existing tags.
+func completeTagArgs(ctx context.Context, s *State, args []string) []string {
+	if len(args) == 0 {
+		return completeFollowedURLs(ctx, s)
+	}
+	user, err := s.Db.GetUser(ctx, s.Config.CurrentUserName)
+	if err != nil {
+		return nil
+	}
+	tags, err := s.Db.GetTagsForUser(ctx, user.ID)
+	if err != nil {
+		return nil
+	}
+	names := []string{}
+	for _, tag := range tags {
+		names = append(names, tag.Tag)
+	}
+	return names
+}
+
+func currentFollows(ctx context.Context, s *State) []database.GetFeedFollowsForUserRow {
+	user, err := s.Db.GetUser(ctx, s.Config.CurrentUserName)
+	if err != nil {
+		return nil
+	}
+	follows, err := s.Db.GetFeedFollowsForUser(ctx, database.GetFeedFollowsForUserParams{UserID: user.ID})
+	if err != nil {
+		return nil
+	}
+	return follows
+}
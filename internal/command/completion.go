@@ -0,0 +1,158 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Fraegdegjevar/Gator/internal/config"
+)
+
+// The shells completion can write a script for.
+var completionShells = []string{"bash", "zsh", "fish"}
+
+// Print a completion script for the shell named. The scripts list the
+// command names themselves and ask "gator __complete" for everything
+// after that, so flags and values from the database stay current
+// without regenerating the script.
+func (c *Commands) handlerCompletion(ctx context.Context, fs config.FileSystem, s *State, cmd Command) error {
+	switch cmd.Args[0] {
+	case "bash":
+		return s.Out.Text(c.bashCompletion())
+	case "zsh":
+		return s.Out.Text(c.zshCompletion())
+	case "fish":
+		return s.Out.Text(c.fishCompletion())
+	default:
+		return cmd.Usage("unsupported shell %q - use one of %v", cmd.Args[0], strings.Join(completionShells, ", "))
+	}
+}
+
+// Print, one per line, the values the last of the words could take. The
+// words are the command line after "gator" up to the cursor, already
+// split and unquoted by the shell; the last is the one being typed and
+// may be empty.
+func (c *Commands) handlerComplete(ctx context.Context, fs config.FileSystem, s *State, cmd Command) error {
+	words := skipGlobalOptions(cmd.Args)
+	partial := ""
+	if len(words) > 0 {
+		partial = words[len(words)-1]
+		words = words[:len(words)-1]
+	}
+	var b strings.Builder
+	for _, candidate := range c.complete(ctx, s, words) {
+		if strings.HasPrefix(candidate, partial) {
+			fmt.Fprintln(&b, candidate)
+		}
+	}
+	return s.Out.Text(b.String())
+}
+
+// skipGlobalOptions drops the options main reads before the command name
+// (--time, --timeout duration and --output format), leaving the word
+// being typed even if it's one of them.
+func skipGlobalOptions(words []string) []string {
+	for len(words) > 1 && strings.HasPrefix(words[0], "--") {
+		if (words[0] == "--timeout" || words[0] == "--output") && len(words) > 2 {
+			words = words[1:]
+		}
+		words = words[1:]
+	}
+	return words
+}
+
+func (c *Commands) bashCompletion() string {
+	return `# bash completion for gator. Load it with
+#   source <(gator completion bash)
+# or save it in /etc/bash_completion.d/gator.
+_gator() {
+    local line=${COMP_LINE:0:COMP_POINT}
+    local -a words candidates
+    read -a words <<< "$line"
+    [[ $line == *[[:space:]] ]] && words+=("")
+    local cur=${words[${#words[@]}-1]}
+
+    local IFS=$'\n'
+    candidates=($(gator __complete -- "${words[@]:1}" 2>/dev/null))
+
+    # Bash splits words at colons, so for a url it only replaces what
+    # follows the last one.
+    local prefix=
+    if [[ $COMP_WORDBREAKS == *:* && $cur == *:* ]]; then
+        prefix=${cur%"${cur##*:}"}
+    fi
+    COMPREPLY=()
+    local candidate
+    for candidate in "${candidates[@]}"; do
+        candidate=$(printf '%q' "$candidate")
+        COMPREPLY+=("${candidate#"$prefix"}")
+    done
+}
+complete -F _gator gator
+`
+}
+
+func (c *Commands) zshCompletion() string {
+	var b strings.Builder
+	b.WriteString(`#compdef gator
+# zsh completion for gator. Load it with
+#   source <(gator completion zsh)
+# or save it as _gator in a directory on $fpath.
+_gator() {
+    local -a commands candidates
+    commands=(
+`)
+	for _, name := range c.Names() {
+		summary := strings.ReplaceAll(c.Registry[name].Summary, ":", `\:`)
+		fmt.Fprintf(&b, "        %v\n", shellSingleQuote(name+":"+summary))
+	}
+	b.WriteString(`    )
+    if (( CURRENT == 2 )); then
+        _describe command commands
+        return
+    fi
+    candidates=(${(f)"$(gator __complete -- "${(@Q)words[2,CURRENT]}" 2>/dev/null)"})
+    compadd -a candidates
+}
+
+if [[ $funcstack[1] == _gator ]]; then
+    _gator "$@"
+else
+    compdef _gator gator
+fi
+`)
+	return b.String()
+}
+
+func (c *Commands) fishCompletion() string {
+	var b strings.Builder
+	b.WriteString(`# fish completion for gator. Load it with
+#   gator completion fish | source
+# or save it as ~/.config/fish/completions/gator.fish.
+function __gator_complete
+    set -l words (commandline -opc) (commandline -ct)
+    gator __complete -- $words[2..-1] 2>/dev/null
+end
+
+complete -c gator -f
+`)
+	for _, name := range c.Names() {
+		fmt.Fprintf(&b, "complete -c gator -n __fish_use_subcommand -a %v -d %v\n",
+			name, fishQuote(c.Registry[name].Summary))
+	}
+	b.WriteString("complete -c gator -n 'not __fish_use_subcommand' -a '(__gator_complete)'\n")
+	return b.String()
+}
+
+// shellSingleQuote quotes s for bash or zsh, where nothing inside single
+// quotes is special and a single quote has to be closed, escaped and
+// reopened.
+func shellSingleQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// fishQuote quotes s for fish, which allows \' and \\ inside single
+// quotes.
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
@@ -0,0 +1,51 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Fraegdegjevar/Gator/internal/rss"
+)
+
+// How long each request made while looking for a feed may take.
+const discoverTimeout = 15 * time.Second
+
+var ErrMultipleFeeds = errors.New("multiple feeds found")
+
+// discoverFeed turns what the user typed - often a blog's homepage
+// rather than its feed - into a single feed url. If the page advertises
+// several feeds, choice (the 1-based index the user picked, or "" if
+// they haven't) selects one; without it the candidates are listed in
+// the returned error so the user can re-run the command with a choice.
+func discoverFeed(ctx context.Context, pageURL, choice string) (rss.Candidate, error) {
+	client := rss.NewClient(discoverTimeout)
+	candidates, err := client.Discover(ctx, pageURL)
+	if err != nil {
+		return rss.Candidate{}, err
+	}
+
+	if choice != "" {
+		n, err := strconv.Atoi(choice)
+		if err != nil || n < 1 || n > len(candidates) {
+			return rss.Candidate{}, fmt.Errorf("feed choice must be a number from 1 to %v, got %q", len(candidates), choice)
+		}
+		return candidates[n-1], nil
+	}
+
+	if len(candidates) == 1 {
+		return candidates[0], nil
+	}
+
+	list := strings.Builder{}
+	for i, candidate := range candidates {
+		fmt.Fprintf(&list, "\n  %v) %v", i+1, candidate.URL)
+		if candidate.Title != "" {
+			fmt.Fprintf(&list, " (%v)", candidate.Title)
+		}
+	}
+	return rss.Candidate{}, fmt.Errorf("%w at %v - re-run with the number of the one you want:%v", ErrMultipleFeeds, pageURL, list.String())
+}
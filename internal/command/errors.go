@@ -0,0 +1,66 @@
+package command
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/lib/pq"
+)
+
+// Errors handlers return for ExitCode to turn into exit codes. ErrUsage and
+// ErrCommandNotFound live with the dispatcher in command.go.
+var (
+	ErrUserNotFound  = errors.New("user not found")
+	ErrUserExists    = errors.New("user already exists")
+	ErrDBUnavailable = errors.New("database unavailable")
+	ErrTimeout       = errors.New("timed out")
+)
+
+// Postgres error codes (https://www.postgresql.org/docs/current/errcodes-appendix.html).
+const (
+	pqUniqueViolation = "23505"
+	// Classes covering failed connections, login and startup.
+	pqClassConnectionException  = "08"
+	pqClassInvalidAuthorization = "28"
+	pqInvalidCatalogName        = "3D000"
+	pqCannotConnectNow          = "57P03"
+)
+
+// isUniqueViolation reports whether err is Postgres rejecting a row
+// that breaks a UNIQUE constraint.
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == pqUniqueViolation
+}
+
+// isConnectionError reports whether err means we couldn't talk to the
+// database at all, as opposed to a query failing.
+func isConnectionError(err error) bool {
+	var netErr *net.OpError
+	if errors.As(err, &netErr) || errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) {
+		return true
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch {
+		case pqErr.Code.Class() == pqClassConnectionException,
+			pqErr.Code.Class() == pqClassInvalidAuthorization,
+			pqErr.Code == pqInvalidCatalogName,
+			pqErr.Code == pqCannotConnectNow:
+			return true
+		}
+	}
+	return false
+}
+
+// dbUnavailable marks connection errors with ErrDBUnavailable, leaving
+// any other error as it is.
+func dbUnavailable(err error) error {
+	if err == nil || errors.Is(err, ErrDBUnavailable) || !isConnectionError(err) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", ErrDBUnavailable, err)
+}
@@ -0,0 +1,61 @@
+package command
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Fraegdegjevar/Gator/internal/config"
+)
+
+// Exit codes, so scripts can tell failures apart without parsing
+// messages.
+const (
+	ExitOK            = 0
+	ExitError         = 1
+	ExitUsage         = 2
+	ExitUserNotFound  = 3
+	ExitUserExists    = 4
+	ExitDBUnavailable = 5
+	ExitTimeout       = 6
+	ExitInterrupted   = 130 // as shells report death by SIGINT
+)
+
+// exitCodes explains each exit code, for help.
+var exitCodes = []struct {
+	code    int
+	meaning string
+}{
+	{ExitOK, "success"},
+	{ExitError, "any error not listed below"},
+	{ExitUsage, "unknown command, or bad flags or arguments"},
+	{ExitUserNotFound, "the user named (or the current user) doesn't exist"},
+	{ExitUserExists, "register with a name that is already taken"},
+	{ExitDBUnavailable, "couldn't connect to postgres"},
+	{ExitTimeout, "the command ran longer than --timeout"},
+	{ExitInterrupted, "interrupted with Ctrl-C"},
+}
+
+// ExitCode maps an error from a command to the code gator exits with.
+// ctx is the context the command ran with, to tell if it was interrupted.
+func ExitCode(ctx context.Context, err error) int {
+	switch {
+	case err == nil:
+		return ExitOK
+	case ctx.Err() != nil:
+		return ExitInterrupted
+	case errors.Is(err, ErrUsage),
+		errors.Is(err, ErrCommandNotFound),
+		errors.Is(err, config.ErrNoUsername):
+		return ExitUsage
+	case errors.Is(err, ErrUserNotFound):
+		return ExitUserNotFound
+	case errors.Is(err, ErrUserExists):
+		return ExitUserExists
+	case errors.Is(err, ErrDBUnavailable):
+		return ExitDBUnavailable
+	case errors.Is(err, ErrTimeout):
+		return ExitTimeout
+	default:
+		return ExitError
+	}
+}
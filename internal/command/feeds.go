@@ -0,0 +1,118 @@
+package command
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Fraegdegjevar/Gator/internal/config"
+)
+
+// A feed as feeds lists it.
+type feedResult struct {
+	Name        string     `json:"name"`
+	URL         string     `json:"url" label:"URL"`
+	AddedBy     string     `json:"added_by" label:"Added by"`
+	LastFetched *time.Time `json:"last_fetched_at" label:"Last fetched"`
+	Fetches     int32      `json:"fetch_count"`
+	CacheHits   int32      `json:"cache_hits" label:"Cache hits"`
+	Status      string     `json:"status"`
+}
+
+// A feed as feeds --broken lists it.
+type brokenFeedResult struct {
+	Name        string     `json:"name"`
+	URL         string     `json:"url" label:"URL"`
+	Failures    int32      `json:"failure_count"`
+	LastError   string     `json:"last_error,omitempty" label:"Last error"`
+	NextAttempt *time.Time `json:"next_fetch_at,omitempty" label:"Next attempt"`
+	Disabled    *time.Time `json:"disabled_at,omitempty" label:"Disabled since"`
+}
+
+// List every feed in the database with who added it and how often agg
+// has been able to skip downloading it thanks to HTTP caching. With
+// --broken, only feeds that are failing or disabled are listed.
+func HandlerFeeds(ctx context.Context, fs config.FileSystem, s *State, cmd Command) error {
+	if cmd.Bool("broken") {
+		return listBrokenFeeds(ctx, s)
+	}
+
+	feeds, err := s.Db.GetFeeds(ctx)
+	if err != nil {
+		return fmt.Errorf("error getting feeds: %w", err)
+	}
+
+	results := []feedResult{}
+	for _, feed := range feeds {
+		status := "ok"
+		if feed.DisabledAt.Valid {
+			status = "disabled"
+		} else if feed.FailureCount > 0 {
+			status = fmt.Sprintf("failing (%v in a row)", feed.FailureCount)
+		}
+		results = append(results, feedResult{
+			Name:        feed.Name,
+			URL:         feed.Url,
+			AddedBy:     feed.UserName,
+			LastFetched: nullTime(feed.LastFetchedAt),
+			Fetches:     feed.FetchCount,
+			CacheHits:   feed.CacheHits,
+			Status:      status,
+		})
+	}
+	return s.Out.List(results, "No feeds found - add one with addfeed.")
+}
+
+func listBrokenFeeds(ctx context.Context, s *State) error {
+	feeds, err := s.Db.GetBrokenFeeds(ctx)
+	if err != nil {
+		return fmt.Errorf("error getting broken feeds: %w", err)
+	}
+
+	results := []brokenFeedResult{}
+	for _, feed := range feeds {
+		result := brokenFeedResult{
+			Name:      feed.Name,
+			URL:       feed.Url,
+			Failures:  feed.FailureCount,
+			LastError: feed.LastError.String,
+			Disabled:  nullTime(feed.DisabledAt),
+		}
+		// A disabled feed isn't retried until re-enabled.
+		if !feed.DisabledAt.Valid {
+			result.NextAttempt = nullTime(feed.NextFetchAt)
+		}
+		results = append(results, result)
+	}
+	return s.Out.List(results, "No broken feeds.")
+}
+
+// Re-enable a feed agg disabled after repeated failures, and clear its
+// failure history so it is fetched on the next tick.
+func HandlerFeedEnable(ctx context.Context, fs config.FileSystem, s *State, cmd Command) error {
+	url := cmd.Args[0]
+
+	updated, err := s.Db.EnableFeed(ctx, url)
+	if err != nil {
+		return fmt.Errorf("error enabling feed: %w", err)
+	}
+	if updated == 0 {
+		return fmt.Errorf("no feed with url %q", url)
+	}
+
+	return s.Out.Result(
+		struct {
+			URL string `json:"url"`
+		}{url},
+		"Feed %v enabled", url)
+}
+
+// nullTime turns an optional time from the database into a result field,
+// nil (and so left out) when unset.
+func nullTime(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
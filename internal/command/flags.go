@@ -0,0 +1,152 @@
+package command
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type FlagType int
+
+const (
+	BoolFlag FlagType = iota
+	StringFlag
+	IntFlag
+)
+
+// A Flag is a --name option a command accepts. Flags may appear anywhere
+// among the arguments, as "--name value" or "--name=value" (boolean
+// flags take no value). A bare "--" ends flag parsing, so everything
+// after it is positional even if it starts with dashes.
+type Flag struct {
+	// Without the leading dashes.
+	Name string
+	Type FlagType
+	// Placeholder for the value in usage text, e.g. "name".
+	Value string
+	// Used by the accessors when the flag isn't given.
+	Default string
+	// One line shown by help.
+	Usage string
+}
+
+// usage renders the flag as it appears in a usage line.
+func (f Flag) usage() string {
+	if f.Type == BoolFlag {
+		return "[--" + f.Name + "]"
+	}
+	return "[--" + f.Name + " " + f.Value + "]"
+}
+
+// parseFlags splits args into positional arguments and the values of the
+// flags d declares, checking each against its type. Arguments after the
+// first d.RawArgs positional ones are all positional.
+func parseFlags(d *Descriptor, args []string) ([]string, map[string]string, error) {
+	positional := []string{}
+	values := make(map[string]string)
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			positional = append(positional, args[i+1:]...)
+			break
+		}
+		if d.RawArgs > 0 && len(positional) == d.RawArgs {
+			positional = append(positional, args[i:]...)
+			break
+		}
+		if !strings.HasPrefix(arg, "--") {
+			positional = append(positional, arg)
+			continue
+		}
+
+		name, value, hasValue := strings.Cut(strings.TrimPrefix(arg, "--"), "=")
+		flag, ok := d.flag(name)
+		if !ok {
+			return nil, nil, fmt.Errorf("unknown flag --%v", name)
+		}
+		if _, ok := values[name]; ok {
+			return nil, nil, fmt.Errorf("flag --%v given more than once", name)
+		}
+
+		switch flag.Type {
+		case BoolFlag:
+			if !hasValue {
+				value = "true"
+			}
+			if _, err := strconv.ParseBool(value); err != nil {
+				return nil, nil, fmt.Errorf("flag --%v must be true or false, got %q", name, value)
+			}
+		default:
+			if !hasValue {
+				if i+1 >= len(args) {
+					return nil, nil, fmt.Errorf("flag --%v needs a value", name)
+				}
+				i++
+				value = args[i]
+			}
+			if flag.Type == IntFlag {
+				if _, err := strconv.Atoi(value); err != nil {
+					return nil, nil, fmt.Errorf("flag --%v must be a whole number, got %q", name, value)
+				}
+			}
+		}
+		values[name] = value
+	}
+	return positional, values, nil
+}
+
+// Lookup returns the value of the flag name and whether it was given.
+func (c Command) Lookup(name string) (string, bool) {
+	value, ok := c.Flags[name]
+	return value, ok
+}
+
+// String returns the value of the flag name, or its default.
+func (c Command) String(name string) string {
+	if value, ok := c.Flags[name]; ok {
+		return value
+	}
+	if c.desc != nil {
+		if flag, ok := c.desc.flag(name); ok {
+			return flag.Default
+		}
+	}
+	return ""
+}
+
+// Bool reports whether the boolean flag name is set.
+func (c Command) Bool(name string) bool {
+	b, _ := strconv.ParseBool(c.String(name))
+	return b
+}
+
+// Int returns the value of the integer flag name, or its default.
+func (c Command) Int(name string) int {
+	n, _ := strconv.Atoi(c.String(name))
+	return n
+}
+
+// Usage builds a usage error for the command, for handlers that find a
+// problem with their arguments Run couldn't check.
+func (c Command) Usage(format string, a ...any) error {
+	reason := fmt.Sprintf(format, a...)
+	if c.desc == nil {
+		return fmt.Errorf("%w: %v", ErrUsage, reason)
+	}
+	return &UsageError{Command: *c.desc, Reason: reason}
+}
+
+// A UsageError is returned when a command is given arguments it can't
+// use. Its message includes the command's help text.
+type UsageError struct {
+	Command Descriptor
+	Reason  string
+}
+
+func (e *UsageError) Error() string {
+	return e.Reason + "\n\n" + strings.TrimSuffix(e.Command.Help(), "\n")
+}
+
+func (e *UsageError) Unwrap() error {
+	return ErrUsage
+}
@@ -0,0 +1,75 @@
+package command
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Fraegdegjevar/Gator/internal/config"
+	"github.com/Fraegdegjevar/Gator/internal/database"
+	"github.com/google/uuid"
+)
+
+// A follow as follow and addfeed report it.
+type followResult struct {
+	User string `json:"user"`
+	Feed string `json:"feed"`
+	URL  string `json:"url"`
+}
+
+// Follow an existing feed (looked up by url) as the current user.
+// The feed itself must already have been added with addfeed. As with
+// addfeed, a web page url is resolved to the feed it links to.
+func HandlerFollow(ctx context.Context, fs config.FileSystem, s *State, cmd Command, user database.User) error {
+	url := cmd.Args[0]
+	choice := ""
+	if len(cmd.Args) == 2 {
+		choice = cmd.Args[1]
+	}
+
+	feed, err := s.Db.GetFeedByURL(ctx, url)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Not a feed we know - maybe it's a page linking to one.
+		candidate, discoverErr := discoverFeed(ctx, url, choice)
+		if discoverErr != nil {
+			return discoverErr
+		}
+		url = candidate.URL
+		feed, err = s.Db.GetFeedByURL(ctx, url)
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("no feed with url %q - add it with addfeed first", url)
+	}
+	if err != nil {
+		return fmt.Errorf("error getting feed from database: %w", err)
+	}
+
+	follow, err := followFeed(ctx, s, user, feed)
+	if err != nil {
+		return err
+	}
+
+	return s.Out.Result(
+		followResult{follow.UserName, follow.FeedName, feed.Url},
+		"%v is now following %v", follow.UserName, follow.FeedName)
+}
+
+// followFeed records that user follows feed. Shared with addfeed, which
+// follows a feed on behalf of the user who added it.
+func followFeed(ctx context.Context, s *State, user database.User, feed database.Feed) (database.CreateFeedFollowRow, error) {
+	follow, err := s.Db.CreateFeedFollow(
+		ctx,
+		database.CreateFeedFollowParams{
+			ID:        uuid.New(),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+			UserID:    user.ID,
+			FeedID:    feed.ID,
+		})
+	if err != nil {
+		return database.CreateFeedFollowRow{}, fmt.Errorf("error following feed %q: %w", feed.Url, err)
+	}
+	return follow, nil
+}
@@ -0,0 +1,58 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Fraegdegjevar/Gator/internal/config"
+	"github.com/Fraegdegjevar/Gator/internal/database"
+)
+
+// A feed as following lists it.
+type followingResult struct {
+	Name   string   `json:"name"`
+	URL    string   `json:"url" label:"URL"`
+	Unread int64    `json:"unread_count"`
+	Tags   []string `json:"tags"`
+}
+
+// List the feeds the current user follows, with how many unread posts
+// each has and its tags. --tag limits the list to feeds with that tag.
+func HandlerFollowing(ctx context.Context, fs config.FileSystem, s *State, cmd Command, user database.User) error {
+	tag := tagFilter(cmd)
+
+	follows, err := s.Db.GetFeedFollowsForUser(
+		ctx,
+		database.GetFeedFollowsForUserParams{
+			UserID: user.ID,
+			Tag:    tag,
+		})
+	if err != nil {
+		return fmt.Errorf("error getting followed feeds: %w", err)
+	}
+
+	tags, err := followTags(ctx, s, user)
+	if err != nil {
+		return err
+	}
+
+	results := []followingResult{}
+	for _, follow := range follows {
+		names := tags[follow.ID]
+		if names == nil {
+			names = []string{}
+		}
+		results = append(results, followingResult{
+			Name:   follow.FeedName,
+			URL:    follow.FeedUrl,
+			Unread: follow.UnreadCount,
+			Tags:   names,
+		})
+	}
+
+	empty := fmt.Sprintf("%v is not following any feeds.", user.Name)
+	if tag.Valid {
+		empty = fmt.Sprintf("%v is not following any feeds tagged %q.", user.Name, tag.String)
+	}
+	return s.Out.List(results, empty)
+}
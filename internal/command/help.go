@@ -0,0 +1,128 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Fraegdegjevar/Gator/internal/config"
+	"github.com/Fraegdegjevar/Gator/internal/output"
+)
+
+// A command as help describes it in JSON output.
+type commandResult struct {
+	Name     string       `json:"name"`
+	Summary  string       `json:"summary"`
+	Usage    string       `json:"usage"`
+	Flags    []flagResult `json:"flags"`
+	Examples []string     `json:"examples"`
+}
+
+type flagResult struct {
+	Name    string `json:"name"`
+	Value   string `json:"value,omitempty"`
+	Default string `json:"default,omitempty"`
+	Usage   string `json:"usage"`
+}
+
+// List every command with its summary, or with an argument show the
+// usage and examples for that command.
+func (c *Commands) handlerHelp(ctx context.Context, fs config.FileSystem, s *State, cmd Command) error {
+	if len(cmd.Args) == 1 {
+		d, ok := c.Registry[cmd.Args[0]]
+		if !ok {
+			return c.notFound(cmd.Args[0])
+		}
+		return showHelp(s, d)
+	}
+
+	names := c.Names()
+	if s.Out.Format() == output.JSON {
+		results := []commandResult{}
+		for _, name := range names {
+			results = append(results, c.Registry[name].result())
+		}
+		return s.Out.List(results, "")
+	}
+
+	width := 0
+	for _, name := range names {
+		width = max(width, len(name))
+	}
+
+	var b strings.Builder
+	fmt.Fprintln(&b, "Usage: gator [--time] [--timeout duration] [--output format] <command> [args]")
+	fmt.Fprintln(&b, "\nCommands:")
+	for _, name := range names {
+		fmt.Fprintf(&b, "  %-*v  %v\n", width, name, c.Registry[name].Summary)
+	}
+	fmt.Fprintln(&b, "\nExit codes:")
+	for _, exit := range exitCodes {
+		fmt.Fprintf(&b, "  %3v  %v\n", exit.code, exit.meaning)
+	}
+	fmt.Fprintln(&b, "\nRun \"gator help <command>\" for details.")
+	return s.Out.Text(b.String())
+}
+
+// showHelp writes the help for one command, for help and --help.
+func showHelp(s *State, d Descriptor) error {
+	if s.Out.Format() == output.JSON {
+		return s.Out.Item(d.result())
+	}
+	return s.Out.Text(d.Help())
+}
+
+func (d Descriptor) result() commandResult {
+	flags := []flagResult{}
+	for _, flag := range d.Flags {
+		flags = append(flags, flagResult{flag.Name, flag.Value, flag.Default, flag.Usage})
+	}
+	examples := d.Examples
+	if examples == nil {
+		examples = []string{}
+	}
+	return commandResult{
+		Name:     d.Name,
+		Summary:  d.Summary,
+		Usage:    d.UsageLine(),
+		Flags:    flags,
+		Examples: examples,
+	}
+}
+
+// Help is the full help text for the command: usage line, summary,
+// flags and examples.
+func (d Descriptor) Help() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Usage: gator %v\n\n", d.UsageLine())
+	fmt.Fprintf(&b, "%v\n", d.Summary)
+
+	if len(d.Flags) > 0 {
+		names := []string{}
+		width := 0
+		for _, flag := range d.Flags {
+			name := "--" + flag.Name
+			if flag.Type != BoolFlag {
+				name += " " + flag.Value
+			}
+			names = append(names, name)
+			width = max(width, len(name))
+		}
+		fmt.Fprintln(&b, "\nFlags:")
+		for i, flag := range d.Flags {
+			fmt.Fprintf(&b, "  %-*v  %v", width, names[i], flag.Usage)
+			if flag.Default != "" {
+				fmt.Fprintf(&b, " (default %v)", flag.Default)
+			}
+			fmt.Fprintln(&b)
+		}
+	}
+
+	if len(d.Examples) > 0 {
+		fmt.Fprintln(&b, "\nExamples:")
+		for _, example := range d.Examples {
+			fmt.Fprintf(&b, "  gator %v\n", example)
+		}
+	}
+	return b.String()
+}
@@ -2,8 +2,9 @@ package command
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
-	"os"
 
 	"github.com/Fraegdegjevar/Gator/internal/config"
 )
@@ -11,22 +12,28 @@ import (
 // Login updates the config current user.
 // but also checks that the user exists in the database
 // before logging in.
-func HandlerLogin(fs config.FileSystem, s *State, cmd Command) error {
+func HandlerLogin(ctx context.Context, fs config.FileSystem, s *State, cmd Command) error {
 	if len(cmd.Args) < 1 {
 		return config.ErrNoUsername
 	}
 	username := cmd.Args[0]
 
-	//Is user in db? Exit with coe 1 if not.
-	_, err := s.Db.GetUser(context.Background(), username)
+	//Is user in db? Don't log in as someone who doesn't exist.
+	_, err := s.Db.GetUser(ctx, username)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: %q - register them first", ErrUserNotFound, username)
+	}
 	if err != nil {
-		fmt.Printf("error getting supplied user from database: %v\n", err)
-		os.Exit(1)
+		return fmt.Errorf("error getting supplied user from database: %w", dbUnavailable(err))
 	}
 
 	err = s.Config.SetUser(fs, username)
 	if err != nil {
 		return fmt.Errorf("login failed: %w", err)
 	}
-	return nil
+	return s.Out.Result(
+		struct {
+			User string `json:"user"`
+		}{username},
+		"Logged in as %v", username)
 }
@@ -0,0 +1,102 @@
+package command
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/Fraegdegjevar/Gator/internal/config"
+	"github.com/Fraegdegjevar/Gator/internal/database"
+)
+
+// Returned (wrapped with the panic value) by Recover when a handler
+// panics.
+var ErrPanic = errors.New("command panicked")
+
+// A Middleware wraps a handler with code that runs before and/or after
+// it. Commands.Use installs middleware around every command.
+type Middleware func(next Handler) Handler
+
+// connectDB connects to the database, if that hasn't happened yet,
+// before calling next. It runs inside any other middleware, so --timeout
+// covers connecting too.
+func connectDB(next Handler) Handler {
+	return func(ctx context.Context, fs config.FileSystem, s *State, cmd Command) error {
+		err := s.connect(ctx)
+		if err != nil {
+			return err
+		}
+		return next(ctx, fs, s, cmd)
+	}
+}
+
+// LoggedInHandler is a handler that acts on behalf of the current user.
+type LoggedInHandler func(context.Context, config.FileSystem, *State, Command, database.User) error
+
+// middlewareLoggedIn resolves Config.CurrentUserName to a database.User
+// before calling handler, so handlers don't each repeat the lookup.
+func middlewareLoggedIn(handler LoggedInHandler) Handler {
+	return func(ctx context.Context, fs config.FileSystem, s *State, cmd Command) error {
+		user, err := s.Db.GetUser(ctx, s.Config.CurrentUserName)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: current user %q - login or register first", ErrUserNotFound, s.Config.CurrentUserName)
+		}
+		if err != nil {
+			return fmt.Errorf("error getting current user %q from database: %w", s.Config.CurrentUserName, dbUnavailable(err))
+		}
+		return handler(ctx, fs, s, cmd, user)
+	}
+}
+
+// Timing writes how long each command took to w once it returns.
+func Timing(w io.Writer) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, fs config.FileSystem, s *State, cmd Command) error {
+			start := time.Now()
+			defer func() {
+				fmt.Fprintf(w, "%v took %v\n", cmd.Name, time.Since(start).Round(time.Millisecond))
+			}()
+			return next(ctx, fs, s, cmd)
+		}
+	}
+}
+
+// Timeout cancels each command's context after d, except for streaming
+// commands like agg that are meant to run until interrupted.
+func Timeout(d time.Duration) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, fs config.FileSystem, s *State, cmd Command) error {
+			if cmd.desc != nil && cmd.desc.Streaming {
+				return next(ctx, fs, s, cmd)
+			}
+			ctx, cancel := context.WithTimeout(ctx, d)
+			defer cancel()
+
+			err := next(ctx, fs, s, cmd)
+			// Postgres reports a cancelled query as its own error rather
+			// than the context's, so check the context itself.
+			if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return fmt.Errorf("%w after %v: %w", ErrTimeout, d, err)
+			}
+			return err
+		}
+	}
+}
+
+// Recover turns a panic in a handler into an ErrPanic error, so deferred
+// cleanup further up still runs and main can exit normally.
+func Recover() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, fs config.FileSystem, s *State, cmd Command) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					err = fmt.Errorf("%w: %v: %v", ErrPanic, cmd.Name, r)
+				}
+			}()
+			return next(ctx, fs, s, cmd)
+		}
+	}
+}
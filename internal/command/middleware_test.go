@@ -0,0 +1,205 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Fraegdegjevar/Gator/internal/config"
+	"github.com/Fraegdegjevar/Gator/internal/database"
+	"github.com/Fraegdegjevar/Gator/internal/testutil"
+)
+
+func TestUseOrder(t *testing.T) {
+	calls := []string{}
+	record := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(ctx context.Context, fs config.FileSystem, s *State, cmd Command) error {
+				calls = append(calls, name+" before")
+				err := next(ctx, fs, s, cmd)
+				calls = append(calls, name+" after")
+				return err
+			}
+		}
+	}
+
+	cmds := NewCommands()
+	cmds.Register(Descriptor{
+		Name: "mock",
+		Handler: func(ctx context.Context, fs config.FileSystem, s *State, cmd Command) error {
+			calls = append(calls, "handler")
+			return nil
+		},
+	})
+	cmds.Use(record("outer"), record("inner"))
+
+	err := cmds.Run(context.Background(), config.OSFileSystem{}, &State{}, Command{Name: "mock"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{"outer before", "inner before", "handler", "inner after", "outer after"}
+	if !reflect.DeepEqual(expected, calls) {
+		t.Errorf("expected calls: %v, got: %v", expected, calls)
+	}
+}
+
+func TestRecover(t *testing.T) {
+	errMock := errors.New("mock error")
+
+	cases := []struct {
+		name          string
+		handler       Handler
+		expectedError error
+	}{
+		{
+			name:          "no panic",
+			handler:       func(ctx context.Context, fs config.FileSystem, s *State, cmd Command) error { return nil },
+			expectedError: nil,
+		},
+		{
+			name:          "error passes through",
+			handler:       func(ctx context.Context, fs config.FileSystem, s *State, cmd Command) error { return errMock },
+			expectedError: errMock,
+		},
+		{
+			name: "panic",
+			handler: func(ctx context.Context, fs config.FileSystem, s *State, cmd Command) error {
+				panic("boom")
+			},
+			expectedError: ErrPanic,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			cmds := NewCommands()
+			cmds.Register(Descriptor{Name: "mock", Handler: tt.handler})
+			cmds.Use(Recover())
+
+			err := cmds.Run(context.Background(), config.OSFileSystem{}, &State{}, Command{Name: "mock"})
+			if !errors.Is(err, tt.expectedError) {
+				t.Errorf("expected error: %v, got: %v", tt.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestTiming(t *testing.T) {
+	out := &bytes.Buffer{}
+	cmds := NewCommands()
+	cmds.Register(Descriptor{
+		Name:    "mock",
+		Handler: func(ctx context.Context, fs config.FileSystem, s *State, cmd Command) error { return nil },
+	})
+	cmds.Use(Timing(out))
+
+	err := cmds.Run(context.Background(), config.OSFileSystem{}, &State{}, Command{Name: "mock"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "mock took ") {
+		t.Errorf("expected timing line for mock, got: %q", out.String())
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	// Waits for the context to end, or gives up quickly if it doesn't.
+	waitForCancel := func(ctx context.Context, fs config.FileSystem, s *State, cmd Command) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(100 * time.Millisecond):
+			return nil
+		}
+	}
+
+	cases := []struct {
+		name          string
+		streaming     bool
+		expectedError error
+	}{
+		{
+			name:          "times out",
+			expectedError: ErrTimeout,
+		},
+		{
+			name:          "streaming command exempt",
+			streaming:     true,
+			expectedError: nil,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			cmds := NewCommands()
+			cmds.Register(Descriptor{Name: "mock", Streaming: tt.streaming, Handler: waitForCancel})
+			cmds.Use(Timeout(time.Millisecond))
+
+			err := cmds.Run(context.Background(), config.OSFileSystem{}, &State{}, Command{Name: "mock"})
+			if !errors.Is(err, tt.expectedError) {
+				t.Errorf("expected error: %v, got: %v", tt.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestMiddlewareLoggedIn(t *testing.T) {
+	tdb := testutil.DB(t)
+	user := testutil.User(t, tdb)
+
+	cases := []struct {
+		name          string
+		currentUser   string
+		expectedError error
+	}{
+		{
+			name:          "no current user",
+			currentUser:   "",
+			expectedError: ErrUserNotFound,
+		},
+		{
+			name:          "unknown current user",
+			currentUser:   "nosuchuser",
+			expectedError: ErrUserNotFound,
+		},
+		{
+			name:          "current user resolved",
+			currentUser:   user.Name,
+			expectedError: nil,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			var called bool
+			var got database.User
+			handler := middlewareLoggedIn(func(ctx context.Context, fs config.FileSystem, s *State, cmd Command, u database.User) error {
+				called = true
+				got = u
+				return nil
+			})
+
+			s := &State{Config: &config.Config{CurrentUserName: tt.currentUser}, Db: database.New(tdb)}
+			err := handler(context.Background(), config.OSFileSystem{}, s, Command{Name: "mock"})
+			if !errors.Is(err, tt.expectedError) {
+				t.Fatalf("expected error: %v, got: %v", tt.expectedError, err)
+			}
+			if tt.expectedError != nil {
+				if called {
+					t.Errorf("expected handler not to be called")
+				}
+				return
+			}
+			if !called {
+				t.Fatalf("expected handler to be called")
+			}
+			if got.ID != user.ID || got.Name != user.Name {
+				t.Errorf("expected user: %v (%v), got: %v (%v)", user.Name, user.ID, got.Name, got.ID)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package command
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Fraegdegjevar/Gator/internal/config"
+	"github.com/Fraegdegjevar/Gator/internal/database"
+	"github.com/Fraegdegjevar/Gator/internal/opml"
+	"github.com/google/uuid"
+)
+
+// Import subscriptions from an OPML file exported by another reader.
+// Feeds missing from the database are added, and the current user
+// follows every feed in the file.
+func HandlerImportOPML(ctx context.Context, fs config.FileSystem, s *State, cmd Command, user database.User) error {
+	doc, err := opml.ReadFile(fs, cmd.Args[0])
+	if err != nil {
+		return fmt.Errorf("import failed: %w", err)
+	}
+
+	// Following a feed twice violates the unique constraint, so work out
+	// what is already followed up front.
+	follows, err := s.Db.GetFeedFollowsForUser(
+		ctx,
+		database.GetFeedFollowsForUserParams{UserID: user.ID})
+	if err != nil {
+		return fmt.Errorf("error getting followed feeds: %w", err)
+	}
+	following := make(map[uuid.UUID]bool)
+	for _, follow := range follows {
+		following[follow.FeedID] = true
+	}
+
+	added, followed := 0, 0
+	for _, opmlFeed := range doc.Feeds() {
+		feed, err := s.Db.GetFeedByURL(ctx, opmlFeed.URL)
+		if errors.Is(err, sql.ErrNoRows) {
+			feed, err = s.Db.CreateFeed(
+				ctx,
+				database.CreateFeedParams{
+					ID:        uuid.New(),
+					CreatedAt: time.Now(),
+					UpdatedAt: time.Now(),
+					Name:      opmlFeed.Name,
+					Url:       opmlFeed.URL,
+					UserID:    user.ID,
+				})
+			if err != nil {
+				return fmt.Errorf("error adding feed %q to database: %w", opmlFeed.URL, err)
+			}
+			added++
+		} else if err != nil {
+			return fmt.Errorf("error getting feed %q from database: %w", opmlFeed.URL, err)
+		}
+
+		if following[feed.ID] {
+			continue
+		}
+		_, err = followFeed(ctx, s, user, feed)
+		if err != nil {
+			return err
+		}
+		following[feed.ID] = true
+		followed++
+	}
+
+	return s.Out.Result(
+		struct {
+			File     string `json:"file"`
+			Added    int    `json:"added"`
+			Followed int    `json:"followed"`
+		}{cmd.Args[0], added, followed},
+		"Imported %v: %v new feeds added, %v feeds followed", cmd.Args[0], added, followed)
+}
+
+// Export the current user's followed feeds as OPML 2.0, either to the
+// given file or to stdout. Tagged feeds are written inside a folder per
+// tag; --tag exports only the feeds with that tag.
+func HandlerExportOPML(ctx context.Context, fs config.FileSystem, s *State, cmd Command, user database.User) error {
+	tag := tagFilter(cmd)
+
+	follows, err := s.Db.GetFeedFollowsForUser(
+		ctx,
+		database.GetFeedFollowsForUserParams{
+			UserID: user.ID,
+			Tag:    tag,
+		})
+	if err != nil {
+		return fmt.Errorf("error getting followed feeds: %w", err)
+	}
+
+	tags, err := followTags(ctx, s, user)
+	if err != nil {
+		return err
+	}
+
+	// A feed with several tags appears once in each tag's folder. When
+	// exporting a single tag, only that folder is written.
+	feeds := []opml.Feed{}
+	for _, follow := range follows {
+		folders := tags[follow.ID]
+		if tag.Valid {
+			folders = []string{tag.String}
+		}
+		if len(folders) == 0 {
+			feeds = append(feeds, opml.Feed{
+				Name:    follow.FeedName,
+				URL:     follow.FeedUrl,
+				Folders: []string{},
+			})
+			continue
+		}
+		for _, folder := range folders {
+			feeds = append(feeds, opml.Feed{
+				Name:    follow.FeedName,
+				URL:     follow.FeedUrl,
+				Folders: []string{folder},
+			})
+		}
+	}
+	doc := opml.New(fmt.Sprintf("gator subscriptions for %v", user.Name), time.Now(), feeds)
+
+	if len(cmd.Args) == 0 {
+		data, err := doc.Marshal()
+		if err != nil {
+			return err
+		}
+		// The OPML is the result, whatever the output format.
+		return s.Out.Text(string(data))
+	}
+
+	err = opml.WriteFile(fs, cmd.Args[0], doc)
+	if err != nil {
+		return fmt.Errorf("export failed: %w", err)
+	}
+	return s.Out.Result(
+		struct {
+			File     string `json:"file"`
+			Exported int    `json:"exported"`
+		}{cmd.Args[0], len(follows)},
+		"Exported %v feeds to %v", len(follows), cmd.Args[0])
+}
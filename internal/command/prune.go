@@ -0,0 +1,43 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Fraegdegjevar/Gator/internal/config"
+	"github.com/Fraegdegjevar/Gator/internal/retention"
+)
+
+// Delete posts outside the retention policy set in config. Starred posts
+// are always kept. With --dry-run nothing is deleted; we only report how
+// many posts would be.
+func HandlerPrune(ctx context.Context, fs config.FileSystem, s *State, cmd Command) error {
+	dryRun := cmd.Bool("dry-run")
+
+	removed, err := retention.Prune(ctx, s.Db, retentionPolicy(s.Config), time.Now(), dryRun)
+	if errors.Is(err, retention.ErrNoPolicy) {
+		return fmt.Errorf("%w - set retention_max_age_days or retention_max_per_feed in ~/.gatorconfig.json", err)
+	}
+	if err != nil {
+		return fmt.Errorf("error pruning posts: %w", err)
+	}
+
+	result := struct {
+		Removed int64 `json:"removed"`
+		DryRun  bool  `json:"dry_run"`
+	}{removed, dryRun}
+	if dryRun {
+		return s.Out.Result(result, "Would remove %v posts.", removed)
+	}
+	return s.Out.Result(result, "Removed %v posts.", removed)
+}
+
+func retentionPolicy(c *config.Config) retention.Policy {
+	return retention.Policy{
+		MaxAge:     time.Duration(c.RetentionMaxAgeDays) * 24 * time.Hour,
+		MaxPerFeed: c.RetentionMaxPerFeed,
+		KeepUnread: c.RetentionKeepUnread,
+	}
+}
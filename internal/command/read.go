@@ -0,0 +1,100 @@
+package command
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/Fraegdegjevar/Gator/internal/config"
+	"github.com/Fraegdegjevar/Gator/internal/database"
+	"github.com/google/uuid"
+)
+
+// Mark a single post (by the ID browse shows) as read by the current
+// user, and print its link so it can be opened.
+func HandlerRead(ctx context.Context, fs config.FileSystem, s *State, cmd Command, user database.User) error {
+	postID, err := parsePostID(cmd.Args[0])
+	if err != nil {
+		return err
+	}
+
+	post, err := s.Db.GetPost(ctx, postID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("no post with id %v", postID)
+	}
+	if err != nil {
+		return fmt.Errorf("error getting post from database: %w", err)
+	}
+
+	_, err = s.Db.MarkPostRead(
+		ctx,
+		database.MarkPostReadParams{
+			UserID: user.ID,
+			PostID: post.ID,
+		})
+	if err != nil {
+		return fmt.Errorf("error marking post read: %w", err)
+	}
+
+	return s.Out.Item(struct {
+		Title string    `json:"title"`
+		Link  string    `json:"url"`
+		ID    uuid.UUID `json:"id"`
+	}{post.Title, post.Url, post.ID})
+}
+
+// Mark every post in the current user's followed feeds as read, or only
+// those in one feed (given by name or url).
+func HandlerMarkAllRead(ctx context.Context, fs config.FileSystem, s *State, cmd Command, user database.User) error {
+	if len(cmd.Args) == 0 {
+		marked, err := s.Db.MarkAllPostsRead(ctx, user.ID)
+		if err != nil {
+			return fmt.Errorf("error marking posts read: %w", err)
+		}
+		return s.Out.Result(
+			struct {
+				Marked int64 `json:"marked"`
+			}{marked},
+			"Marked %v posts read", marked)
+	}
+
+	// Only feeds the user follows make sense here, so look the feed up
+	// among those rather than in every feed.
+	follows, err := s.Db.GetFeedFollowsForUser(
+		ctx,
+		database.GetFeedFollowsForUserParams{UserID: user.ID})
+	if err != nil {
+		return fmt.Errorf("error getting followed feeds: %w", err)
+	}
+	for _, follow := range follows {
+		if follow.FeedUrl != cmd.Args[0] && follow.FeedName != cmd.Args[0] {
+			continue
+		}
+		marked, err := s.Db.MarkFeedPostsRead(
+			ctx,
+			database.MarkFeedPostsReadParams{
+				UserID: user.ID,
+				FeedID: follow.FeedID,
+			})
+		if err != nil {
+			return fmt.Errorf("error marking posts read: %w", err)
+		}
+		return s.Out.Result(
+			struct {
+				Feed   string `json:"feed"`
+				Marked int64  `json:"marked"`
+			}{follow.FeedName, marked},
+			"Marked %v posts in %v read", marked, follow.FeedName)
+	}
+	return fmt.Errorf("%v is not following a feed named %q", user.Name, cmd.Args[0])
+}
+
+// parsePostID validates a post ID typed by the user (as shown by browse).
+func parsePostID(arg string) (uuid.UUID, error) {
+	postID, err := uuid.Parse(arg)
+	if err != nil {
+		return uuid.UUID{}, fmt.Errorf("invalid post id %q: %w", arg, err)
+	}
+	return postID, nil
+}
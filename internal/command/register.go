@@ -3,7 +3,6 @@ package command
 import (
 	"context"
 	"fmt"
-	"os"
 	"time"
 
 	"github.com/Fraegdegjevar/Gator/internal/config"
@@ -11,42 +10,42 @@ import (
 	"github.com/google/uuid"
 )
 
+// A user as register shows them.
+type userResult struct {
+	Name    string    `json:"name"`
+	ID      uuid.UUID `json:"id"`
+	Created time.Time `json:"created_at"`
+}
+
 // 'Register' the user (name is provided) in the database and set them as the
 // current user in config.
-// Exits program with code 1 if user with same name already exists.
-func HandlerRegister(fs config.FileSystem, s *State, cmd Command) error {
+// Returns ErrUserExists if a user with the same name already exists.
+func HandlerRegister(ctx context.Context, fs config.FileSystem, s *State, cmd Command) error {
 	if len(cmd.Args) != 1 {
-		return fmt.Errorf("must supply one user to register")
+		return cmd.Usage("must supply one user to register")
 	}
-	// access the db query object inside state to execute our sql query
-	// to create a user in DB. CreateUSer needs context.Background
-	// (empty Context) + CreateUSer params (i.e see db schema - uuid,
-	// creted_at, updated_at, name).
-	// If fail - returns
+	// Create the user in the DB, translating the unique constraint on
+	// name into something friendlier than a Postgres error code.
 	user, err := s.Db.CreateUser(
-		context.Background(),
+		ctx,
 		database.CreateUserParams{
 			ID:        uuid.New(),
 			CreatedAt: time.Now(),
 			UpdatedAt: time.Now(),
 			Name:      cmd.Args[0],
 		})
+	if isUniqueViolation(err) {
+		return fmt.Errorf("%w: %q", ErrUserExists, cmd.Args[0])
+	}
 	if err != nil {
-		fmt.Printf("error adding user to database: %v\n", err)
-		os.Exit(1)
+		return fmt.Errorf("error adding user to database: %w", dbUnavailable(err))
 	}
 
-	//Otherwise, we succeeded in adding to the db. Print details
-	// and set current user
-	fmt.Println("user was created in database:")
-	fmt.Printf("Name: %v\n", user.Name)
-	fmt.Printf("UUID: %v\n", user.ID)
-	fmt.Printf("Created At: %v\n", user.CreatedAt)
-	fmt.Printf("Updated At: %v\n", user.UpdatedAt)
-
+	//Otherwise, we succeeded in adding to the db. Set them as
+	// current user and show their details.
 	err = s.Config.SetUser(fs, cmd.Args[0])
 	if err != nil {
 		return err
 	}
-	return nil
+	return s.Out.Item(userResult{user.Name, user.ID, user.CreatedAt})
 }
@@ -0,0 +1,259 @@
+package command
+
+// Shared by every command that can be narrowed to one tag.
+var tagFlag = Flag{
+	Name:  "tag",
+	Type:  StringFlag,
+	Value: "name",
+	Usage: "Only include feeds with this tag",
+}
+
+// Builtins describes every command gator ships with, in the order they
+// are registered.
+func Builtins() []Descriptor {
+	return []Descriptor{
+		{
+			Name:     "login",
+			Summary:  "Switch the current user to an existing user",
+			Usage:    "<username>",
+			MinArgs:  1,
+			MaxArgs:  1,
+			Examples: []string{"login alice"},
+			Complete: firstArg(completeUsernames),
+			NeedsDB:  true,
+			Handler:  HandlerLogin,
+		},
+		{
+			Name:     "register",
+			Summary:  "Create a user and log in as them",
+			Usage:    "<username>",
+			MinArgs:  1,
+			MaxArgs:  1,
+			Examples: []string{"register alice"},
+			NeedsDB:  true,
+			Handler:  HandlerRegister,
+		},
+		{
+			Name:    "reset",
+			Summary: "Delete every user, and with them all feeds and follows",
+			NeedsDB: true,
+			Handler: HandlerReset,
+		},
+		{
+			Name:    "addfeed",
+			Summary: "Add a feed and follow it",
+			Usage:   "<name> <url> [choice]",
+			MinArgs: 2,
+			MaxArgs: 3,
+			Examples: []string{
+				"addfeed \"Go blog\" https://go.dev/blog/feed.atom",
+				"addfeed \"Some blog\" https://example.com 2",
+			},
+			NeedsDB: true,
+			Handler: middlewareLoggedIn(HandlerAddFeed),
+		},
+		{
+			Name:    "feeds",
+			Summary: "List every feed, or only failing ones with --broken",
+			Flags: []Flag{
+				{Name: "broken", Type: BoolFlag, Usage: "Only list feeds that are failing or disabled"},
+			},
+			Examples: []string{"feeds", "feeds --broken"},
+			NeedsDB:  true,
+			Handler:  HandlerFeeds,
+		},
+		{
+			Name:     "feed-enable",
+			Summary:  "Re-enable a feed agg disabled after repeated failures",
+			Usage:    "<url>",
+			MinArgs:  1,
+			MaxArgs:  1,
+			Examples: []string{"feed-enable https://go.dev/blog/feed.atom"},
+			Complete: firstArg(completeFeedURLs),
+			NeedsDB:  true,
+			Handler:  HandlerFeedEnable,
+		},
+		{
+			Name:     "follow",
+			Summary:  "Follow a feed, discovering it from a web page if needed",
+			Usage:    "<url> [choice]",
+			MinArgs:  1,
+			MaxArgs:  2,
+			Examples: []string{"follow https://go.dev/blog/feed.atom", "follow https://example.com 2"},
+			Complete: firstArg(completeFeedURLs),
+			NeedsDB:  true,
+			Handler:  middlewareLoggedIn(HandlerFollow),
+		},
+		{
+			Name:     "unfollow",
+			Summary:  "Stop following a feed",
+			Usage:    "<url>",
+			MinArgs:  1,
+			MaxArgs:  1,
+			Examples: []string{"unfollow https://go.dev/blog/feed.atom"},
+			Complete: firstArg(completeFollowedURLs),
+			NeedsDB:  true,
+			Handler:  middlewareLoggedIn(HandlerUnfollow),
+		},
+		{
+			Name:     "following",
+			Summary:  "List the feeds you follow with unread counts and tags",
+			Flags:    []Flag{tagFlag},
+			Examples: []string{"following", "following --tag go"},
+			NeedsDB:  true,
+			Handler:  middlewareLoggedIn(HandlerFollowing),
+		},
+		{
+			Name:     "tag",
+			Summary:  "Tag a followed feed",
+			Usage:    "<feed-url> <tag>...",
+			MinArgs:  2,
+			MaxArgs:  -1,
+			Examples: []string{"tag https://go.dev/blog/feed.atom go programming"},
+			Complete: completeTagArgs,
+			NeedsDB:  true,
+			Handler:  middlewareLoggedIn(HandlerTag),
+		},
+		{
+			Name:    "untag",
+			Summary: "Remove tags from a followed feed, or all of them",
+			Usage:   "<feed-url> [tag]...",
+			MinArgs: 1,
+			MaxArgs: -1,
+			Examples: []string{
+				"untag https://go.dev/blog/feed.atom programming",
+				"untag https://go.dev/blog/feed.atom",
+			},
+			Complete: completeTagArgs,
+			NeedsDB:  true,
+			Handler:  middlewareLoggedIn(HandlerUntag),
+		},
+		{
+			Name:    "tags",
+			Summary: "List your tags with feed and unread counts",
+			NeedsDB: true,
+			Handler: middlewareLoggedIn(HandlerTags),
+		},
+		{
+			Name:      "agg",
+			Summary:   "Fetch feeds every interval until interrupted",
+			Usage:     "<interval>",
+			MinArgs:   1,
+			MaxArgs:   1,
+			Examples:  []string{"agg 1m", "agg 30s"},
+			Streaming: true,
+			NeedsDB:   true,
+			Handler:   HandlerAgg,
+		},
+		{
+			Name:    "browse",
+			Summary: "Show recent posts from the feeds you follow",
+			Usage:   "[limit]",
+			MaxArgs: 1,
+			Flags: []Flag{
+				{Name: "unread", Type: BoolFlag, Usage: "Hide posts you have already read"},
+				tagFlag,
+			},
+			Examples: []string{"browse", "browse 10", "browse --unread --tag go"},
+			NeedsDB:  true,
+			Handler:  middlewareLoggedIn(HandlerBrowse),
+		},
+		{
+			Name:     "read",
+			Summary:  "Mark a post read",
+			Usage:    "<post-id>",
+			MinArgs:  1,
+			MaxArgs:  1,
+			Examples: []string{"read 3f2b8e9c-6f1d-4c55-9a57-1d1c4e0f6a2b"},
+			NeedsDB:  true,
+			Handler:  middlewareLoggedIn(HandlerRead),
+		},
+		{
+			Name:     "mark-all-read",
+			Summary:  "Mark every post read, or only those in one feed",
+			Usage:    "[feed]",
+			MaxArgs:  1,
+			Examples: []string{"mark-all-read", "mark-all-read \"Go blog\""},
+			Complete: firstArg(completeFollowedNames),
+			NeedsDB:  true,
+			Handler:  middlewareLoggedIn(HandlerMarkAllRead),
+		},
+		{
+			Name:    "star",
+			Summary: "Save a post to come back to, with an optional note",
+			Usage:   "<post-id> [note]",
+			MinArgs: 1,
+			MaxArgs: -1,
+			Examples: []string{
+				"star 3f2b8e9c-6f1d-4c55-9a57-1d1c4e0f6a2b",
+				"star 3f2b8e9c-6f1d-4c55-9a57-1d1c4e0f6a2b read this weekend",
+			},
+			NeedsDB: true,
+			Handler: middlewareLoggedIn(HandlerStar),
+		},
+		{
+			Name:     "unstar",
+			Summary:  "Remove a post from your starred posts",
+			Usage:    "<post-id>",
+			MinArgs:  1,
+			MaxArgs:  1,
+			Examples: []string{"unstar 3f2b8e9c-6f1d-4c55-9a57-1d1c4e0f6a2b"},
+			NeedsDB:  true,
+			Handler:  middlewareLoggedIn(HandlerUnstar),
+		},
+		{
+			Name:    "starred",
+			Summary: "List your starred posts",
+			NeedsDB: true,
+			Handler: middlewareLoggedIn(HandlerStarred),
+		},
+		{
+			Name:    "search",
+			Summary: "Search posts in the feeds you follow",
+			Usage:   "<query>...",
+			MinArgs: 1,
+			MaxArgs: -1,
+			Flags: []Flag{
+				{Name: "feed", Type: StringFlag, Value: "name", Usage: "Only search posts from this feed"},
+				{Name: "since", Type: StringFlag, Value: "date", Usage: "Only search posts published on or after this date (YYYY-MM-DD)"},
+				{Name: "limit", Type: IntFlag, Value: "n", Default: "10", Usage: "Show at most this many results"},
+			},
+			Examples: []string{
+				"search generics",
+				"search \"error handling\" --feed \"Go blog\" --since 2025-01-01",
+			},
+			NeedsDB: true,
+			Handler: middlewareLoggedIn(HandlerSearch),
+		},
+		{
+			Name:    "prune",
+			Summary: "Delete old posts according to the retention policy",
+			Flags: []Flag{
+				{Name: "dry-run", Type: BoolFlag, Usage: "Report how many posts would be deleted without deleting them"},
+			},
+			Examples: []string{"prune --dry-run", "prune"},
+			NeedsDB:  true,
+			Handler:  HandlerPrune,
+		},
+		{
+			Name:     "import-opml",
+			Summary:  "Follow every feed in an OPML file",
+			Usage:    "<file>",
+			MinArgs:  1,
+			MaxArgs:  1,
+			Examples: []string{"import-opml subscriptions.opml"},
+			NeedsDB:  true,
+			Handler:  middlewareLoggedIn(HandlerImportOPML),
+		},
+		{
+			Name:     "export-opml",
+			Summary:  "Write the feeds you follow as OPML, with tags as folders",
+			Usage:    "[file]",
+			MaxArgs:  1,
+			Flags:    []Flag{tagFlag},
+			Examples: []string{"export-opml", "export-opml subscriptions.opml", "export-opml --tag go go.opml"},
+			NeedsDB:  true,
+			Handler:  middlewareLoggedIn(HandlerExportOPML),
+		},
+	}
+}
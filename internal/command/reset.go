@@ -2,19 +2,28 @@ package command
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/Fraegdegjevar/Gator/internal/config"
 )
 
-func HandlerReset(fs config.FileSystem, s *State, cmd Command) error {
+func HandlerReset(ctx context.Context, fs config.FileSystem, s *State, cmd Command) error {
 	// No args required - just reset and confirm.
-	err := s.Db.DeleteUsers(context.Background())
+	err := s.Db.DeleteUsers(ctx)
 
 	if err != nil {
 		return err
 	}
 
-	fmt.Println("Successfully reset all users.")
-	return nil
+	// Deleting the users' feeds cascades here too, but reset should leave
+	// nothing behind whatever the schema does.
+	err = s.Db.DeletePrunedPosts(ctx)
+	if err != nil {
+		return err
+	}
+
+	return s.Out.Result(
+		struct {
+			Reset bool `json:"reset"`
+		}{true},
+		"Successfully reset all users.")
 }
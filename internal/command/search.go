@@ -0,0 +1,93 @@
+package command
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Fraegdegjevar/Gator/internal/config"
+	"github.com/Fraegdegjevar/Gator/internal/database"
+	"github.com/google/uuid"
+)
+
+// A post as search shows it, with the matching text highlighted.
+type searchResult struct {
+	Title     string     `json:"title"`
+	Feed      string     `json:"feed"`
+	Published *time.Time `json:"published_at,omitempty" label:"Date"`
+	Snippet   string     `json:"snippet"`
+	Link      string     `json:"url"`
+	ID        uuid.UUID  `json:"id"`
+}
+
+// Dates accepted by search --since.
+var sinceLayouts = []string{
+	"2006-01-02",
+	time.RFC3339,
+}
+
+// Full-text search over posts in the current user's followed feeds. The
+// query uses web search syntax ("quoted phrases", -excluded, or). Results
+// are ranked by relevance with matches highlighted in **bold** markers.
+// --feed limits results to one feed (by name), --since to posts
+// published on or after a date (YYYY-MM-DD) and --limit caps how many
+// results are shown.
+func HandlerSearch(ctx context.Context, fs config.FileSystem, s *State, cmd Command, user database.User) error {
+	query := strings.Join(cmd.Args, " ")
+
+	feedName := sql.NullString{}
+	if name, ok := cmd.Lookup("feed"); ok {
+		feedName = sql.NullString{String: name, Valid: true}
+	}
+	since := sql.NullTime{}
+	if date, ok := cmd.Lookup("since"); ok {
+		t, err := parseSince(date)
+		if err != nil {
+			return cmd.Usage("%v", err)
+		}
+		since = sql.NullTime{Time: t, Valid: true}
+	}
+	limit := cmd.Int("limit")
+	if limit < 1 {
+		return cmd.Usage("limit must be a positive whole number, got %v", limit)
+	}
+
+	results, err := s.Db.SearchPosts(
+		ctx,
+		database.SearchPostsParams{
+			Query:      query,
+			UserID:     user.ID,
+			FeedName:   feedName,
+			Since:      since,
+			MaxResults: int32(limit),
+		})
+	if err != nil {
+		return fmt.Errorf("error searching posts: %w", err)
+	}
+
+	// Results are already in order of relevance.
+	matches := []searchResult{}
+	for _, result := range results {
+		matches = append(matches, searchResult{
+			Title:     result.Title,
+			Feed:      result.FeedName,
+			Published: nullTime(result.PublishedAt),
+			Snippet:   strings.Join(strings.Fields(result.Snippet), " "),
+			Link:      result.Url,
+			ID:        result.ID,
+		})
+	}
+	return s.Out.List(matches, fmt.Sprintf("No posts found matching %q.", query))
+}
+
+func parseSince(arg string) (time.Time, error) {
+	for _, layout := range sinceLayouts {
+		t, err := time.Parse(layout, arg)
+		if err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid --since date %q - use YYYY-MM-DD", arg)
+}
@@ -0,0 +1,62 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/Fraegdegjevar/Gator/internal/config"
+	"github.com/Fraegdegjevar/Gator/internal/shell"
+)
+
+// Shell history lives in the home directory alongside the config file.
+const historyFileName = ".gator_history"
+
+// How many lines of shell history are kept.
+const historySize = 1000
+
+// Read and run commands until exit, keeping one State (and so one
+// database connection pool) open for the whole session rather than
+// reconnecting for every command.
+func (c *Commands) handlerShell(ctx context.Context, fs config.FileSystem, s *State, cmd Command) error {
+	// Checked here rather than on the typed name, so an alias for the
+	// shell is caught too.
+	if s.inShell {
+		return fmt.Errorf("already in the shell")
+	}
+	s.inShell = true
+	defer func() { s.inShell = false }()
+
+	home, err := fs.GetUserHomeDir()
+	if err != nil {
+		return fmt.Errorf("error finding home directory for shell history: %w", err)
+	}
+	historyFile := home + "/" + historyFileName
+	history, err := shell.LoadHistory(fs, historyFile, historySize)
+	if err != nil {
+		return err
+	}
+	// Lines piped in from a script aren't history worth keeping.
+	interactive := shell.Interactive(os.Stdin)
+	if !interactive {
+		historyFile = ""
+	}
+
+	sh := &shell.Shell{
+		Prompt: "gator> ",
+		Run: func(ctx context.Context, args []string) error {
+			return c.Run(ctx, fs, s, Command{Name: args[0], Args: args[1:]})
+		},
+		Candidates: func(ctx context.Context, words []string) []string {
+			return c.complete(ctx, s, words)
+		},
+		History:     history,
+		HistoryFile: historyFile,
+		FS:          fs,
+	}
+
+	if interactive {
+		s.Out.Status("Type help to list commands, and exit or Ctrl-D to leave.")
+	}
+	return sh.Loop(ctx, os.Stdin, os.Stdout, os.Stderr)
+}
@@ -0,0 +1,112 @@
+package command
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Fraegdegjevar/Gator/internal/config"
+	"github.com/Fraegdegjevar/Gator/internal/database"
+	"github.com/google/uuid"
+)
+
+// A post as starred lists it.
+type starredResult struct {
+	Title   string    `json:"title"`
+	Feed    string    `json:"feed"`
+	Starred time.Time `json:"starred_at"`
+	Note    string    `json:"note,omitempty"`
+	Link    string    `json:"url"`
+	ID      uuid.UUID `json:"id"`
+}
+
+// Star a post for the current user so it's easy to find again, with an
+// optional note. Starring an already starred post replaces its note.
+// Starred posts are never pruned.
+func HandlerStar(ctx context.Context, fs config.FileSystem, s *State, cmd Command, user database.User) error {
+	postID, err := parsePostID(cmd.Args[0])
+	if err != nil {
+		return err
+	}
+	// Let the note be typed without quotes.
+	note := strings.Join(cmd.Args[1:], " ")
+
+	post, err := s.Db.GetPost(ctx, postID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("no post with id %v", postID)
+	}
+	if err != nil {
+		return fmt.Errorf("error getting post from database: %w", err)
+	}
+
+	_, err = s.Db.StarPost(
+		ctx,
+		database.StarPostParams{
+			UserID:    user.ID,
+			PostID:    post.ID,
+			Note:      sql.NullString{String: note, Valid: note != ""},
+			CreatedAt: time.Now(),
+		})
+	if err != nil {
+		return fmt.Errorf("error starring post: %w", err)
+	}
+
+	return s.Out.Result(
+		struct {
+			ID    uuid.UUID `json:"id"`
+			Title string    `json:"title"`
+			Note  string    `json:"note,omitempty"`
+		}{post.ID, post.Title, note},
+		"Starred: %v", post.Title)
+}
+
+// Remove the current user's star from a post.
+func HandlerUnstar(ctx context.Context, fs config.FileSystem, s *State, cmd Command, user database.User) error {
+	postID, err := parsePostID(cmd.Args[0])
+	if err != nil {
+		return err
+	}
+
+	deleted, err := s.Db.UnstarPost(
+		ctx,
+		database.UnstarPostParams{
+			UserID: user.ID,
+			PostID: postID,
+		})
+	if err != nil {
+		return fmt.Errorf("error unstarring post: %w", err)
+	}
+	if deleted == 0 {
+		return fmt.Errorf("%v has not starred post %v", user.Name, postID)
+	}
+
+	return s.Out.Result(
+		struct {
+			ID uuid.UUID `json:"id"`
+		}{postID},
+		"Unstarred post %v", postID)
+}
+
+// List the current user's starred posts, most recently starred first.
+func HandlerStarred(ctx context.Context, fs config.FileSystem, s *State, cmd Command, user database.User) error {
+	posts, err := s.Db.GetStarredPostsForUser(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("error getting starred posts: %w", err)
+	}
+
+	results := []starredResult{}
+	for _, post := range posts {
+		results = append(results, starredResult{
+			Title:   post.Title,
+			Feed:    post.FeedName,
+			Starred: post.StarredAt,
+			Note:    post.Note.String,
+			Link:    post.Url,
+			ID:      post.ID,
+		})
+	}
+	return s.Out.List(results, "No starred posts.")
+}
@@ -1,11 +1,41 @@
 package command
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/Fraegdegjevar/Gator/internal/config"
 	"github.com/Fraegdegjevar/Gator/internal/database"
+	"github.com/Fraegdegjevar/Gator/internal/output"
 )
 
 type State struct {
 	Config *config.Config
-	Db     *database.Queries
+	// Nil until a command that needs the database runs: Run connects
+	// with Connect then and keeps the connection for later commands.
+	Db *database.Queries
+	// Opens the database, checking it can actually be reached.
+	Connect func(ctx context.Context) (*database.Queries, error)
+	// Where commands write their results, in the format chosen with
+	// --output. Nil writes plain output to stdout.
+	Out *output.Writer
+
+	// Set while the shell is running, so it isn't started inside itself.
+	inShell bool
+}
+
+// connect sets Db on first use. A failed attempt is retried next time.
+func (s *State) connect(ctx context.Context) error {
+	if s.Db != nil {
+		return nil
+	}
+	if s.Connect == nil {
+		return fmt.Errorf("%w: no database configured", ErrDBUnavailable)
+	}
+	db, err := s.Connect(ctx)
+	if err != nil {
+		return err
+	}
+	s.Db = db
+	return nil
 }
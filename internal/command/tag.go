@@ -0,0 +1,167 @@
+package command
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/Fraegdegjevar/Gator/internal/config"
+	"github.com/Fraegdegjevar/Gator/internal/database"
+	"github.com/google/uuid"
+)
+
+// The tags tag added or untag removed.
+type tagResult struct {
+	Feed string   `json:"feed"`
+	URL  string   `json:"url"`
+	Tags []string `json:"tags"`
+}
+
+// A tag as tags lists it.
+type tagCountResult struct {
+	Tag    string `json:"tag"`
+	Feeds  int64  `json:"feed_count"`
+	Unread int64  `json:"unread_count"`
+}
+
+// Tag one of the current user's follows so related feeds can be browsed,
+// listed and exported together. Tags belong to the follow, so each user
+// organises feeds their own way and unfollowing drops them.
+func HandlerTag(ctx context.Context, fs config.FileSystem, s *State, cmd Command, user database.User) error {
+	tags, err := parseTags(cmd.Args[1:])
+	if err != nil {
+		return err
+	}
+
+	follow, err := findFollow(ctx, s, user, cmd.Args[0])
+	if err != nil {
+		return err
+	}
+
+	for _, tag := range tags {
+		_, err := s.Db.TagFeedFollow(
+			ctx,
+			database.TagFeedFollowParams{
+				FeedFollowID: follow.ID,
+				Tag:          tag,
+			})
+		if err != nil {
+			return fmt.Errorf("error tagging %v with %q: %w", follow.FeedName, tag, err)
+		}
+	}
+
+	return s.Out.Result(
+		tagResult{follow.FeedName, follow.FeedUrl, tags},
+		"Tagged %v with %v", follow.FeedName, strings.Join(tags, ", "))
+}
+
+// Remove tags from one of the current user's follows, or every tag if
+// none are given.
+func HandlerUntag(ctx context.Context, fs config.FileSystem, s *State, cmd Command, user database.User) error {
+	tags, err := parseTags(cmd.Args[1:])
+	if err != nil {
+		return err
+	}
+
+	follow, err := findFollow(ctx, s, user, cmd.Args[0])
+	if err != nil {
+		return err
+	}
+
+	if len(tags) == 0 {
+		removed, err := s.Db.ClearFeedFollowTags(ctx, follow.ID)
+		if err != nil {
+			return fmt.Errorf("error removing tags from %v: %w", follow.FeedName, err)
+		}
+		return s.Out.Result(
+			struct {
+				Feed    string `json:"feed"`
+				URL     string `json:"url"`
+				Removed int64  `json:"removed"`
+			}{follow.FeedName, follow.FeedUrl, removed},
+			"Removed %v tags from %v", removed, follow.FeedName)
+	}
+
+	for _, tag := range tags {
+		removed, err := s.Db.UntagFeedFollow(
+			ctx,
+			database.UntagFeedFollowParams{
+				FeedFollowID: follow.ID,
+				Tag:          tag,
+			})
+		if err != nil {
+			return fmt.Errorf("error removing tag %q from %v: %w", tag, follow.FeedName, err)
+		}
+		if removed == 0 {
+			return fmt.Errorf("%v is not tagged %q", follow.FeedName, tag)
+		}
+	}
+
+	return s.Out.Result(
+		tagResult{follow.FeedName, follow.FeedUrl, tags},
+		"Removed %v from %v", strings.Join(tags, ", "), follow.FeedName)
+}
+
+// List the current user's tags with how many feeds carry each and how
+// many unread posts those feeds have between them.
+func HandlerTags(ctx context.Context, fs config.FileSystem, s *State, cmd Command, user database.User) error {
+	tags, err := s.Db.GetTagsForUser(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("error getting tags: %w", err)
+	}
+
+	results := []tagCountResult{}
+	for _, tag := range tags {
+		results = append(results, tagCountResult{tag.Tag, tag.FeedCount, tag.UnreadCount})
+	}
+	return s.Out.List(results, "No tags yet - add some with tag <feed-url> <tag>.")
+}
+
+// findFollow looks up the current user's follow of the feed with url.
+func findFollow(ctx context.Context, s *State, user database.User, url string) (database.GetFeedFollowsForUserRow, error) {
+	follows, err := s.Db.GetFeedFollowsForUser(
+		ctx,
+		database.GetFeedFollowsForUserParams{UserID: user.ID})
+	if err != nil {
+		return database.GetFeedFollowsForUserRow{}, fmt.Errorf("error getting followed feeds: %w", err)
+	}
+	for _, follow := range follows {
+		if follow.FeedUrl == url {
+			return follow, nil
+		}
+	}
+	return database.GetFeedFollowsForUserRow{}, fmt.Errorf("%v is not following %q", user.Name, url)
+}
+
+// followTags maps each of the user's follows to its tags, in tag order.
+func followTags(ctx context.Context, s *State, user database.User) (map[uuid.UUID][]string, error) {
+	rows, err := s.Db.GetFeedFollowTagsForUser(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting tags: %w", err)
+	}
+	tags := make(map[uuid.UUID][]string)
+	for _, row := range rows {
+		tags[row.FeedFollowID] = append(tags[row.FeedFollowID], row.Tag)
+	}
+	return tags, nil
+}
+
+func parseTags(args []string) ([]string, error) {
+	tags := []string{}
+	for _, arg := range args {
+		tag := strings.TrimSpace(arg)
+		if tag == "" {
+			return nil, fmt.Errorf("tags can't be empty")
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// tagFilter turns the --tag flag into the filter the follow and post
+// queries take.
+func tagFilter(cmd Command) sql.NullString {
+	tag, ok := cmd.Lookup("tag")
+	return sql.NullString{String: tag, Valid: ok}
+}
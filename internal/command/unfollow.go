@@ -0,0 +1,35 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Fraegdegjevar/Gator/internal/config"
+	"github.com/Fraegdegjevar/Gator/internal/database"
+)
+
+// Stop the current user following the feed with the given url. The feed
+// itself is left in the database for any other followers.
+func HandlerUnfollow(ctx context.Context, fs config.FileSystem, s *State, cmd Command, user database.User) error {
+	url := cmd.Args[0]
+
+	deleted, err := s.Db.DeleteFeedFollow(
+		ctx,
+		database.DeleteFeedFollowParams{
+			UserID: user.ID,
+			Url:    url,
+		})
+	if err != nil {
+		return fmt.Errorf("error unfollowing feed: %w", err)
+	}
+	if deleted == 0 {
+		return fmt.Errorf("%v is not following %q", user.Name, url)
+	}
+
+	return s.Out.Result(
+		struct {
+			User string `json:"user"`
+			URL  string `json:"url"`
+		}{user.Name, url},
+		"%v has unfollowed %v", user.Name, url)
+}
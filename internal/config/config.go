@@ -10,9 +10,26 @@ const configFileName = ".gatorconfig.json"
 // exported error
 var ErrNoUsername = errors.New("no username supplied")
 
+var ErrNoAlias = errors.New("no such alias")
+
 type Config struct {
 	DBURL           string `json:"db_url"`
 	CurrentUserName string `json:"current_user_name"`
+	// Tuning for the agg scraper. Zero means use the scraper's default.
+	AggWorkers         int     `json:"agg_workers,omitempty"`
+	AggBatchSize       int     `json:"agg_batch_size,omitempty"`
+	AggHostConcurrency int     `json:"agg_host_concurrency,omitempty"`
+	AggHostRate        float64 `json:"agg_host_rate,omitempty"`
+	AggMaxFailures     int     `json:"agg_max_failures,omitempty"`
+	// Prune the posts table from agg using the retention policy below.
+	AggAutoPrune bool `json:"agg_auto_prune,omitempty"`
+	// Retention policy used by prune. Zero disables a rule.
+	RetentionMaxAgeDays int  `json:"retention_max_age_days,omitempty"`
+	RetentionMaxPerFeed int  `json:"retention_max_per_feed,omitempty"`
+	RetentionKeepUnread bool `json:"retention_keep_unread,omitempty"`
+	// Names that run other commands, e.g. "b": "browse --unread". Several
+	// commands can be separated with ";".
+	Aliases map[string]string `json:"aliases,omitempty"`
 }
 
 func getConfigFilePath(fs FileSystem) (string, error) {
@@ -37,6 +54,35 @@ func (c *Config) SetUser(fs FileSystem, username string) error {
 		return fmt.Errorf("error setting user in configuration file: %w", err)
 	}
 
-	fmt.Printf("user has been set: %v\n", username)
+	return nil
+}
+
+// SetAlias saves name as an alias for expansion, replacing any alias
+// with that name. Whether the alias makes sense is up to the caller.
+func (c *Config) SetAlias(fs FileSystem, name, expansion string) error {
+	if c.Aliases == nil {
+		c.Aliases = make(map[string]string)
+	}
+	c.Aliases[name] = expansion
+
+	err := write(fs, c)
+	if err != nil {
+		return fmt.Errorf("error saving alias in configuration file: %w", err)
+	}
+	return nil
+}
+
+// RemoveAlias deletes the alias name, returning ErrNoAlias if there is
+// no alias by that name.
+func (c *Config) RemoveAlias(fs FileSystem, name string) error {
+	if _, ok := c.Aliases[name]; !ok {
+		return fmt.Errorf("%w: %q", ErrNoAlias, name)
+	}
+	delete(c.Aliases, name)
+
+	err := write(fs, c)
+	if err != nil {
+		return fmt.Errorf("error removing alias from configuration file: %w", err)
+	}
 	return nil
 }
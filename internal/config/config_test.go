@@ -219,3 +219,44 @@ func TestSetUser(t *testing.T) {
 		})
 	}
 }
+
+func TestAliases(t *testing.T) {
+	fs := &FakeFileSystem{Homedir: "tst", Files: make(map[string][]byte)}
+	conf := &Config{CurrentUserName: "default"}
+
+	err := conf.SetAlias(fs, "b", "browse --unread")
+	if err != nil {
+		t.Fatalf("unexpected error setting alias: %v", err)
+	}
+	err = conf.SetAlias(fs, "t", "tags")
+	if err != nil {
+		t.Fatalf("unexpected error setting alias: %v", err)
+	}
+
+	readConf, err := Read(fs)
+	if err != nil {
+		t.Fatalf("failed to read config: %v", err)
+	}
+	expected := map[string]string{"b": "browse --unread", "t": "tags"}
+	if !reflect.DeepEqual(readConf.Aliases, expected) {
+		t.Errorf("expected aliases in file: %v, got: %v", expected, readConf.Aliases)
+	}
+
+	err = conf.RemoveAlias(fs, "t")
+	if err != nil {
+		t.Fatalf("unexpected error removing alias: %v", err)
+	}
+	err = conf.RemoveAlias(fs, "t")
+	if !errors.Is(err, ErrNoAlias) {
+		t.Errorf("expected error: %v, got: %v", ErrNoAlias, err)
+	}
+
+	readConf, err = Read(fs)
+	if err != nil {
+		t.Fatalf("failed to read config: %v", err)
+	}
+	expected = map[string]string{"b": "browse --unread"}
+	if !reflect.DeepEqual(readConf.Aliases, expected) {
+		t.Errorf("expected aliases in file: %v, got: %v", expected, readConf.Aliases)
+	}
+}
@@ -11,7 +11,6 @@ func Read(fs FileSystem) (Config, error) {
 	if err != nil {
 		return Config{}, err
 	}
-	fmt.Printf("getConfigFilePath returned path: %v\n", filePath)
 
 	file, err := fs.ReadFile(filePath)
 	if err != nil {
@@ -0,0 +1,124 @@
+package database_test
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Fraegdegjevar/Gator/internal/database"
+	"github.com/Fraegdegjevar/Gator/internal/testutil"
+)
+
+func TestTags(t *testing.T) {
+	tdb := testutil.DB(t)
+	ctx := context.Background()
+	now := time.Now()
+	tx := testutil.Tx(t, tdb)
+	q := database.New(tx)
+
+	// golang is tagged dev and news, rust just dev, and weather has no
+	// tags. Each feed has two posts, one of golang's read.
+	user := testutil.User(t, tx)
+	follows := map[string]database.Feed{}
+	for i, name := range []string{"golang", "rust", "weather"} {
+		feed := testutil.Feed(t, tx, user, name)
+		follows[name] = feed
+		followID := testutil.Follow(t, tx, user, feed)
+		tags := map[string][]string{"golang": {"dev", "news"}, "rust": {"dev"}}[name]
+		for _, tag := range tags {
+			if _, err := q.TagFeedFollow(ctx, database.TagFeedFollowParams{FeedFollowID: followID, Tag: tag}); err != nil {
+				t.Fatalf("error tagging follow: %v", err)
+			}
+		}
+		first := testutil.Post(t, tx, feed, name+" first", "", now.Add(-time.Duration(2*i+2)*time.Hour))
+		testutil.Post(t, tx, feed, name+" second", "", now.Add(-time.Duration(2*i+1)*time.Hour))
+		if name == "golang" {
+			if _, err := q.MarkPostRead(ctx, database.MarkPostReadParams{UserID: user.ID, PostID: first.ID}); err != nil {
+				t.Fatalf("error marking post read: %v", err)
+			}
+		}
+	}
+
+	// Another user's tags on the same feed don't count.
+	other := testutil.User(t, tx)
+	otherFollow := testutil.Follow(t, tx, other, follows["weather"])
+	if _, err := q.TagFeedFollow(ctx, database.TagFeedFollowParams{FeedFollowID: otherFollow, Tag: "dev"}); err != nil {
+		t.Fatalf("error tagging follow: %v", err)
+	}
+
+	t.Run("tags", func(t *testing.T) {
+		tags, err := q.GetTagsForUser(ctx, user.ID)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		expected := []database.GetTagsForUserRow{
+			{Tag: "dev", FeedCount: 2, UnreadCount: 3},
+			{Tag: "news", FeedCount: 1, UnreadCount: 1},
+		}
+		if !reflect.DeepEqual(expected, tags) {
+			t.Errorf("expected tags: %v, got: %v", expected, tags)
+		}
+	})
+
+	cases := []struct {
+		name            string
+		tag             sql.NullString
+		expectedFollows []string
+		expectedPosts   []string
+	}{
+		{
+			name:            "no tag",
+			expectedFollows: []string{"golang", "rust", "weather"},
+			expectedPosts:   []string{"golang second", "golang first", "rust second", "rust first", "weather second", "weather first"},
+		},
+		{
+			name:            "dev",
+			tag:             sql.NullString{String: "dev", Valid: true},
+			expectedFollows: []string{"golang", "rust"},
+			expectedPosts:   []string{"golang second", "golang first", "rust second", "rust first"},
+		},
+		{
+			name:            "news",
+			tag:             sql.NullString{String: "news", Valid: true},
+			expectedFollows: []string{"golang"},
+			expectedPosts:   []string{"golang second", "golang first"},
+		},
+		{
+			name:            "unknown tag",
+			tag:             sql.NullString{String: "nosuchtag", Valid: true},
+			expectedFollows: []string{},
+			expectedPosts:   []string{},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run("following "+tt.name, func(t *testing.T) {
+			rows, err := q.GetFeedFollowsForUser(ctx, database.GetFeedFollowsForUserParams{UserID: user.ID, Tag: tt.tag})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			names := []string{}
+			for _, r := range rows {
+				names = append(names, r.FeedName)
+			}
+			if !reflect.DeepEqual(tt.expectedFollows, names) {
+				t.Errorf("expected follows: %v, got: %v", tt.expectedFollows, names)
+			}
+		})
+		t.Run("browse "+tt.name, func(t *testing.T) {
+			rows, err := q.GetPostsForUser(ctx, database.GetPostsForUserParams{UserID: user.ID, Tag: tt.tag, MaxPosts: 10})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			titles := []string{}
+			for _, r := range rows {
+				titles = append(titles, r.Title)
+			}
+			if !reflect.DeepEqual(tt.expectedPosts, titles) {
+				t.Errorf("expected posts: %v, got: %v", tt.expectedPosts, titles)
+			}
+		})
+	}
+}
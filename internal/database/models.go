@@ -5,11 +5,75 @@
 package database
 
 import (
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+type Feed struct {
+	ID            uuid.UUID
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	Name          string
+	Url           string
+	UserID        uuid.UUID
+	LastFetchedAt sql.NullTime
+	Etag          sql.NullString
+	LastModified  sql.NullString
+	FetchCount    int32
+	CacheHits     int32
+	FailureCount  int32
+	LastError     sql.NullString
+	NextFetchAt   sql.NullTime
+	DisabledAt    sql.NullTime
+}
+
+type FeedFollow struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	UserID    uuid.UUID
+	FeedID    uuid.UUID
+}
+
+type FeedFollowTag struct {
+	FeedFollowID uuid.UUID
+	Tag          string
+	CreatedAt    time.Time
+}
+
+type Post struct {
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	Title        string
+	Url          string
+	Description  sql.NullString
+	PublishedAt  sql.NullTime
+	FeedID       uuid.UUID
+	SearchVector interface{}
+}
+
+type PostRead struct {
+	UserID uuid.UUID
+	PostID uuid.UUID
+	ReadAt time.Time
+}
+
+type PostStar struct {
+	UserID    uuid.UUID
+	PostID    uuid.UUID
+	Note      sql.NullString
+	CreatedAt time.Time
+}
+
+type PrunedPost struct {
+	FeedID   uuid.UUID
+	Url      string
+	PrunedAt time.Time
+}
+
 type User struct {
 	ID        uuid.UUID
 	CreatedAt time.Time
@@ -0,0 +1,102 @@
+package database_test
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Fraegdegjevar/Gator/internal/database"
+	"github.com/Fraegdegjevar/Gator/internal/testutil"
+)
+
+func TestSearchPosts(t *testing.T) {
+	tdb := testutil.DB(t)
+	ctx := context.Background()
+	now := time.Now()
+	day := 24 * time.Hour
+	tx := testutil.Tx(t, tdb)
+	q := database.New(tx)
+
+	user := testutil.User(t, tx)
+	golang := testutil.Feed(t, tx, user, "golang")
+	rust := testutil.Feed(t, tx, user, "rust")
+	testutil.Follow(t, tx, user, golang)
+	testutil.Follow(t, tx, user, rust)
+	testutil.Post(t, tx, golang, "Generics in Go", "<p>Type parameters have landed</p>", now.Add(-day))
+	testutil.Post(t, tx, golang, "Weekly roundup", "<p>This week: generics, modules and more</p>", now.Add(-10*day))
+	testutil.Post(t, tx, rust, "Rust notes", "<p>Notes on <b>generics</b> and traits</p>", now.Add(-2*day))
+
+	// Posts in feeds the user doesn't follow never match.
+	other := testutil.User(t, tx)
+	elsewhere := testutil.Feed(t, tx, other, "elsewhere")
+	testutil.Follow(t, tx, other, elsewhere)
+	testutil.Post(t, tx, elsewhere, "Generics elsewhere", "", now)
+
+	cases := []struct {
+		name            string
+		query           string
+		feedName        sql.NullString
+		since           sql.NullTime
+		expectedTitles  []string
+		expectedSnippet string
+	}{
+		{
+			name:  "title matches rank first",
+			query: "generics",
+			// The description matches tie on rank, so the newer comes
+			// first.
+			expectedTitles: []string{"Generics in Go", "Rust notes", "Weekly roundup"},
+		},
+		{
+			name:           "feed",
+			query:          "generics",
+			feedName:       sql.NullString{String: "golang", Valid: true},
+			expectedTitles: []string{"Generics in Go", "Weekly roundup"},
+		},
+		{
+			name:           "since",
+			query:          "generics",
+			since:          sql.NullTime{Time: now.Add(-5 * day), Valid: true},
+			expectedTitles: []string{"Generics in Go", "Rust notes"},
+		},
+		{
+			name:           "no match",
+			query:          "kubernetes",
+			expectedTitles: []string{},
+		},
+		{
+			name:            "snippet highlights matches without markup",
+			query:           "traits",
+			expectedTitles:  []string{"Rust notes"},
+			expectedSnippet: "Notes on generics and **traits**",
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := q.SearchPosts(ctx, database.SearchPostsParams{
+				Query:      tt.query,
+				UserID:     user.ID,
+				FeedName:   tt.feedName,
+				Since:      tt.since,
+				MaxResults: 10,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			titles := []string{}
+			for _, r := range results {
+				titles = append(titles, r.Title)
+			}
+			if !reflect.DeepEqual(tt.expectedTitles, titles) {
+				t.Fatalf("expected titles: %v, got: %v", tt.expectedTitles, titles)
+			}
+			if tt.expectedSnippet != "" && !strings.Contains(results[0].Snippet, tt.expectedSnippet) {
+				t.Errorf("expected snippet containing: %q, got: %q", tt.expectedSnippet, results[0].Snippet)
+			}
+		})
+	}
+}
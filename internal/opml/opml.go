@@ -0,0 +1,192 @@
+package opml
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/Fraegdegjevar/Gator/internal/config"
+)
+
+var ErrNotOPML = errors.New("file is not an OPML document")
+
+// OPML mirrors an OPML 2.0 document (http://opml.org/spec2.opml). Feed
+// readers use it to exchange subscription lists.
+type OPML struct {
+	XMLName xml.Name `xml:"opml"`
+	Version string   `xml:"version,attr"`
+	Head    Head     `xml:"head"`
+	Body    Body     `xml:"body"`
+}
+
+type Head struct {
+	Title       string `xml:"title"`
+	DateCreated string `xml:"dateCreated,omitempty"`
+}
+
+type Body struct {
+	Outlines []Outline `xml:"outline"`
+}
+
+// An Outline is either a subscription (it has an xmlUrl) or a folder
+// containing more outlines. Folders can be nested arbitrarily deep.
+type Outline struct {
+	Text     string    `xml:"text,attr"`
+	Title    string    `xml:"title,attr,omitempty"`
+	Type     string    `xml:"type,attr,omitempty"`
+	XMLURL   string    `xml:"xmlUrl,attr,omitempty"`
+	HTMLURL  string    `xml:"htmlUrl,attr,omitempty"`
+	Outlines []Outline `xml:"outline"`
+}
+
+// Feed is a single subscription pulled out of (or to be written to) an
+// OPML document. Folders is the path of folder names it was nested in,
+// outermost first.
+type Feed struct {
+	Name    string
+	URL     string
+	Folders []string
+}
+
+// New builds an OPML 2.0 document listing feeds. Feeds with Folders are
+// nested in folder outlines, created in the order they're first seen.
+func New(title string, created time.Time, feeds []Feed) *OPML {
+	doc := &OPML{
+		Version: "2.0",
+		Head: Head{
+			Title:       title,
+			DateCreated: created.Format(time.RFC1123Z),
+		},
+	}
+	for _, feed := range feeds {
+		doc.Body.Outlines = insert(doc.Body.Outlines, feed.Folders, Outline{
+			Text:   feed.Name,
+			Title:  feed.Name,
+			Type:   "rss",
+			XMLURL: feed.URL,
+		})
+	}
+	return doc
+}
+
+// insert adds outline to outlines under the folder path, creating any
+// folders along it that don't exist yet.
+func insert(outlines []Outline, folders []string, outline Outline) []Outline {
+	if len(folders) == 0 {
+		return append(outlines, outline)
+	}
+	for i := range outlines {
+		if outlines[i].XMLURL == "" && outlines[i].Text == folders[0] {
+			outlines[i].Outlines = insert(outlines[i].Outlines, folders[1:], outline)
+			return outlines
+		}
+	}
+	folder := Outline{
+		Text:     folders[0],
+		Title:    folders[0],
+		Outlines: insert(nil, folders[1:], outline),
+	}
+	return append(outlines, folder)
+}
+
+func Parse(data []byte) (*OPML, error) {
+	doc := &OPML{}
+	err := xml.Unmarshal(data, doc)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrNotOPML, err)
+	}
+	return doc, nil
+}
+
+// Feeds flattens the outline tree into the subscriptions it contains.
+func (o *OPML) Feeds() []Feed {
+	feeds := []Feed{}
+	var walk func(outlines []Outline, folders []string)
+	walk = func(outlines []Outline, folders []string) {
+		for _, outline := range outlines {
+			if outline.XMLURL != "" {
+				name := outline.Title
+				if name == "" {
+					name = outline.Text
+				}
+				if name == "" {
+					name = outline.XMLURL
+				}
+				feeds = append(feeds, Feed{
+					Name:    name,
+					URL:     outline.XMLURL,
+					Folders: folders,
+				})
+				continue
+			}
+
+			// No xmlUrl - treat as a folder. Copy the path so sibling
+			// folders don't share (and overwrite) a backing array.
+			folder := outline.Text
+			if folder == "" {
+				folder = outline.Title
+			}
+			nested := append(append([]string{}, folders...), folder)
+			walk(outline.Outlines, nested)
+		}
+	}
+	walk(o.Body.Outlines, []string{})
+	return feeds
+}
+
+// Marshal renders the document as indented XML with the XML header.
+func (o *OPML) Marshal() ([]byte, error) {
+	data, err := xml.MarshalIndent(o, "", "	")
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling OPML: %w", err)
+	}
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
+
+// ReadFile reads and parses the OPML file at path. Relative paths are
+// resolved against the filesystem's working directory.
+func ReadFile(fs config.FileSystem, path string) (*OPML, error) {
+	path, err := resolvePath(fs, path)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := fs.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file %v: %w", path, err)
+	}
+	return Parse(data)
+}
+
+// WriteFile writes the document to path, resolving it the same way as
+// ReadFile.
+func WriteFile(fs config.FileSystem, path string, doc *OPML) error {
+	path, err := resolvePath(fs, path)
+	if err != nil {
+		return err
+	}
+
+	data, err := doc.Marshal()
+	if err != nil {
+		return err
+	}
+
+	err = fs.WriteFile(path, data, 0644)
+	if err != nil {
+		return fmt.Errorf("error writing file %v: %w", path, err)
+	}
+	return nil
+}
+
+func resolvePath(fs config.FileSystem, path string) (string, error) {
+	if filepath.IsAbs(path) {
+		return path, nil
+	}
+	wd, err := fs.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("error getting working directory: %w", err)
+	}
+	return filepath.Join(wd, path), nil
+}
@@ -0,0 +1,168 @@
+package opml
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Fraegdegjevar/Gator/internal/config"
+)
+
+var testOPML = `<?xml version="1.0" encoding="UTF-8"?>
+<opml version="2.0">
+	<head><title>Subscriptions</title></head>
+	<body>
+		<outline text="Top level" type="rss" xmlUrl="https://example.com/feed.xml"/>
+		<outline text="Tech">
+			<outline text="Go blog" title="The Go Blog" type="rss" xmlUrl="https://go.dev/blog/feed.atom"/>
+			<outline text="Databases">
+				<outline text="Postgres" type="rss" xmlUrl="https://www.postgresql.org/news.rss"/>
+			</outline>
+		</outline>
+		<outline text="Empty folder"/>
+		<outline type="rss" xmlUrl="https://example.com/untitled.xml"/>
+	</body>
+</opml>`
+
+func TestReadFile(t *testing.T) {
+	fs := &config.FakeFileSystem{
+		Wd: "/work",
+		Files: map[string][]byte{
+			"/work/subs.opml":  []byte(testOPML),
+			"/work/notes.txt":  []byte("not xml at all"),
+			"/abs/subs.opml":   []byte(testOPML),
+			"/work/empty.opml": []byte(`<opml version="2.0"><head/><body/></opml>`),
+		},
+	}
+
+	allFeeds := []Feed{
+		{Name: "Top level", URL: "https://example.com/feed.xml", Folders: []string{}},
+		{Name: "The Go Blog", URL: "https://go.dev/blog/feed.atom", Folders: []string{"Tech"}},
+		{Name: "Postgres", URL: "https://www.postgresql.org/news.rss", Folders: []string{"Tech", "Databases"}},
+		{Name: "https://example.com/untitled.xml", URL: "https://example.com/untitled.xml", Folders: []string{}},
+	}
+
+	cases := []struct {
+		name          string
+		path          string
+		expectedFeeds []Feed
+		expectedError error
+	}{
+		{
+			name:          "relative path with nested folders",
+			path:          "subs.opml",
+			expectedFeeds: allFeeds,
+		},
+		{
+			name:          "absolute path",
+			path:          "/abs/subs.opml",
+			expectedFeeds: allFeeds,
+		},
+		{
+			name:          "no feeds",
+			path:          "empty.opml",
+			expectedFeeds: []Feed{},
+		},
+		{
+			name:          "not opml",
+			path:          "notes.txt",
+			expectedError: ErrNotOPML,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := ReadFile(fs, tt.path)
+			if !errors.Is(err, tt.expectedError) {
+				t.Fatalf("expected error: %v, got: %v", tt.expectedError, err)
+			}
+			if err != nil {
+				return
+			}
+			if feeds := doc.Feeds(); !reflect.DeepEqual(tt.expectedFeeds, feeds) {
+				t.Errorf("expected feeds: %v, got: %v", tt.expectedFeeds, feeds)
+			}
+		})
+	}
+}
+
+func TestReadFileMissing(t *testing.T) {
+	fs := &config.FakeFileSystem{Wd: "/work", Files: map[string][]byte{}}
+	_, err := ReadFile(fs, "missing.opml")
+	if err == nil {
+		t.Errorf("expected error reading missing file, got nil")
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	fs := &config.FakeFileSystem{Wd: "/work", Files: map[string][]byte{}}
+	feeds := []Feed{
+		{Name: "Go blog", URL: "https://go.dev/blog/feed.atom", Folders: []string{}},
+		{Name: "Q&A <weekly>", URL: "https://example.com/feed?a=1&b=2", Folders: []string{}},
+	}
+	created := time.Date(2025, time.January, 6, 10, 0, 0, 0, time.UTC)
+
+	err := WriteFile(fs, "out.opml", New("gator subscriptions", created, feeds))
+	if err != nil {
+		t.Fatalf("unexpected error writing OPML: %v", err)
+	}
+
+	// Round trip - what we write must parse back to the same feeds.
+	doc, err := ReadFile(fs, "/work/out.opml")
+	if err != nil {
+		t.Fatalf("error reading written OPML: %v", err)
+	}
+	if doc.Version != "2.0" {
+		t.Errorf("expected version 2.0, got: %q", doc.Version)
+	}
+	if doc.Head.DateCreated != "Mon, 06 Jan 2025 10:00:00 +0000" {
+		t.Errorf("expected RFC 822 dateCreated, got: %q", doc.Head.DateCreated)
+	}
+	if got := doc.Feeds(); !reflect.DeepEqual(feeds, got) {
+		t.Errorf("expected feeds: %v, got: %v", feeds, got)
+	}
+}
+
+func TestNewFolders(t *testing.T) {
+	feeds := []Feed{
+		{Name: "Go blog", URL: "https://go.dev/blog/feed.atom", Folders: []string{"Tech"}},
+		{Name: "Top level", URL: "https://example.com/feed.xml", Folders: []string{}},
+		{Name: "Postgres", URL: "https://www.postgresql.org/news.rss", Folders: []string{"Tech", "Databases"}},
+		{Name: "Go blog", URL: "https://go.dev/blog/feed.atom", Folders: []string{"Go"}},
+	}
+	doc := New("gator", time.Now(), feeds)
+
+	// Folders keep the order they were first seen in, so the top level
+	// is Tech, the unfiled feed, then Go.
+	names := []string{}
+	for _, outline := range doc.Body.Outlines {
+		names = append(names, outline.Text)
+	}
+	expectedNames := []string{"Tech", "Top level", "Go"}
+	if !reflect.DeepEqual(expectedNames, names) {
+		t.Errorf("expected top level outlines: %v, got: %v", expectedNames, names)
+	}
+
+	expectedFeeds := []Feed{
+		{Name: "Go blog", URL: "https://go.dev/blog/feed.atom", Folders: []string{"Tech"}},
+		{Name: "Postgres", URL: "https://www.postgresql.org/news.rss", Folders: []string{"Tech", "Databases"}},
+		{Name: "Top level", URL: "https://example.com/feed.xml", Folders: []string{}},
+		{Name: "Go blog", URL: "https://go.dev/blog/feed.atom", Folders: []string{"Go"}},
+	}
+	if got := doc.Feeds(); !reflect.DeepEqual(expectedFeeds, got) {
+		t.Errorf("expected feeds: %v, got: %v", expectedFeeds, got)
+	}
+}
+
+func TestWriteFileFails(t *testing.T) {
+	fs := &config.FakeFileSystem{
+		Wd:                   "/work",
+		Files:                map[string][]byte{},
+		WriteFileShouldError: config.ErrWriteFail,
+	}
+	err := WriteFile(fs, "out.opml", New("gator", time.Now(), nil))
+	if !errors.Is(err, config.ErrWriteFail) {
+		t.Errorf("expected error: %v, got: %v", config.ErrWriteFail, err)
+	}
+}
@@ -0,0 +1,249 @@
+package output
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// How times are shown in plain and table output. JSON uses RFC 3339.
+const TimeFormat = "Mon 2 Jan 2006 15:04"
+
+// A Format selects how results are rendered.
+type Format string
+
+const (
+	// Plain is the default: each result's first field as a heading, the
+	// rest indented below it one per line.
+	Plain Format = "plain"
+	// Table puts one result per row under a heading for each field.
+	Table Format = "table"
+	// JSON writes each result as a JSON object (or array of them).
+	JSON Format = "json"
+)
+
+var Formats = []Format{Plain, Table, JSON}
+
+var ErrUnknownFormat = errors.New("unknown output format")
+
+func ParseFormat(s string) (Format, error) {
+	for _, format := range Formats {
+		if s == string(format) {
+			return format, nil
+		}
+	}
+	return "", fmt.Errorf("%w %q - use plain, table or json", ErrUnknownFormat, s)
+}
+
+// A Writer renders command results in one format, for people or for
+// scripts. Results are structs (or slices of them) whose fields are
+// shown in declaration order: the json tag names each field in JSON
+// output and the label tag gives its heading in plain and table output,
+// defaulting to the field name. A nil *Writer writes plain output to
+// stdout.
+type Writer struct {
+	out    io.Writer
+	log    io.Writer
+	format Format
+}
+
+// New returns a Writer rendering results to out. log receives progress
+// messages that aren't part of the result - those go to out too unless
+// the format is JSON, so that out holds nothing but the JSON.
+func New(out, log io.Writer, format Format) *Writer {
+	return &Writer{out: out, log: log, format: format}
+}
+
+var stdout = New(os.Stdout, os.Stderr, Plain)
+
+func (w *Writer) orStdout() *Writer {
+	if w == nil {
+		return stdout
+	}
+	return w
+}
+
+func (w *Writer) Format() Format {
+	return w.orStdout().format
+}
+
+// List renders items, a slice of structs. When there are none, plain
+// and table output show empty instead (JSON shows an empty array).
+func (w *Writer) List(items any, empty string) error {
+	w = w.orStdout()
+	v := reflect.ValueOf(items)
+	if v.Kind() != reflect.Slice {
+		return fmt.Errorf("output: List needs a slice, got %T", items)
+	}
+	if w.format == JSON {
+		if v.IsNil() {
+			items = reflect.MakeSlice(v.Type(), 0, 0).Interface()
+		}
+		return w.json(items)
+	}
+	if v.Len() == 0 {
+		_, err := fmt.Fprintln(w.out, empty)
+		return err
+	}
+
+	records := make([]record, v.Len())
+	for i := range v.Len() {
+		records[i] = newRecord(v.Index(i))
+	}
+	if w.format == Table {
+		return w.table(records)
+	}
+	return w.plain(records)
+}
+
+// Item renders a single struct.
+func (w *Writer) Item(item any) error {
+	w = w.orStdout()
+	switch w.format {
+	case JSON:
+		return w.json(item)
+	case Table:
+		return w.table([]record{newRecord(reflect.ValueOf(item))})
+	default:
+		return w.plain([]record{newRecord(reflect.ValueOf(item))})
+	}
+}
+
+// Result reports what a command did: as a sentence built from format
+// and args, or in JSON output as v.
+func (w *Writer) Result(v any, format string, args ...any) error {
+	w = w.orStdout()
+	if w.format == JSON {
+		return w.json(v)
+	}
+	_, err := fmt.Fprintf(w.out, format+"\n", args...)
+	return err
+}
+
+// Status prints progress that isn't part of a command's result.
+func (w *Writer) Status(format string, args ...any) {
+	fmt.Fprintf(w.Log(), format+"\n", args...)
+}
+
+// Log is where Status writes, for passing on to code that reports its
+// own progress.
+func (w *Writer) Log() io.Writer {
+	w = w.orStdout()
+	if w.format == JSON {
+		return w.log
+	}
+	return w.out
+}
+
+// Text writes text that is the same in every format, like help or a
+// generated file.
+func (w *Writer) Text(text string) error {
+	_, err := io.WriteString(w.orStdout().out, text)
+	return err
+}
+
+func (w *Writer) json(v any) error {
+	enc := json.NewEncoder(w.out)
+	enc.SetIndent("", "  ")
+	// Usage lines like "<url>" aren't going anywhere near HTML.
+	enc.SetEscapeHTML(false)
+	return enc.Encode(v)
+}
+
+// First field as a heading, the rest indented below it, skipping any
+// without a value. Results are separated by a blank line.
+func (w *Writer) plain(records []record) error {
+	var b strings.Builder
+	for i, r := range records {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		for j, f := range r {
+			switch {
+			case j == 0:
+				fmt.Fprintf(&b, "%v\n", f.value)
+			case f.value != "":
+				fmt.Fprintf(&b, "  %v: %v\n", f.label, f.value)
+			}
+		}
+	}
+	_, err := io.WriteString(w.out, b.String())
+	return err
+}
+
+func (w *Writer) table(records []record) error {
+	tw := tabwriter.NewWriter(w.out, 0, 0, 2, ' ', 0)
+	labels := []string{}
+	for _, f := range records[0] {
+		labels = append(labels, strings.ToUpper(f.label))
+	}
+	fmt.Fprintln(tw, strings.Join(labels, "\t"))
+	for _, r := range records {
+		values := []string{}
+		for _, f := range r {
+			// Tabs and newlines would break the columns.
+			values = append(values, strings.Join(strings.Fields(f.value), " "))
+		}
+		fmt.Fprintln(tw, strings.Join(values, "\t"))
+	}
+	return tw.Flush()
+}
+
+// A record is one result's fields, formatted for plain or table output.
+type record []field
+
+type field struct {
+	label string
+	value string
+}
+
+func newRecord(v reflect.Value) record {
+	for v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+	r := record{}
+	for i := range v.NumField() {
+		sf := v.Type().Field(i)
+		if !sf.IsExported() || sf.Tag.Get("json") == "-" {
+			continue
+		}
+		label := sf.Tag.Get("label")
+		if label == "" {
+			label = sf.Name
+		}
+		r = append(r, field{label: label, value: formatValue(v.Field(i))})
+	}
+	return r
+}
+
+// formatValue shows a field's value for people: times in TimeFormat,
+// lists comma separated, yes or no for bools and nothing for nil.
+func formatValue(v reflect.Value) string {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	switch x := v.Interface().(type) {
+	case time.Time:
+		if x.IsZero() {
+			return ""
+		}
+		return x.Format(TimeFormat)
+	case bool:
+		if x {
+			return "yes"
+		}
+		return "no"
+	case []string:
+		return strings.Join(x, ", ")
+	}
+	return fmt.Sprint(v.Interface())
+}
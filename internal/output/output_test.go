@@ -0,0 +1,106 @@
+package output
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Fraegdegjevar/Gator/internal/testutil"
+)
+
+type post struct {
+	Title     string     `json:"title"`
+	Feed      string     `json:"feed"`
+	Published *time.Time `json:"published_at,omitempty" label:"Date"`
+	Unread    bool       `json:"unread"`
+	Tags      []string   `json:"tags"`
+	Link      string     `json:"url"`
+	internal  string
+}
+
+func TestWriter(t *testing.T) {
+	published := time.Date(2025, time.March, 4, 9, 30, 0, 0, time.UTC)
+	posts := []post{
+		{
+			Title:     "Go 1.24 is released",
+			Feed:      "The Go Blog",
+			Published: &published,
+			Unread:    true,
+			Tags:      []string{"go", "releases"},
+			Link:      "https://go.dev/blog/go1.24",
+			internal:  "not shown",
+		},
+		{
+			Title: "An undated\tpost",
+			Feed:  "Example",
+			Tags:  []string{},
+			Link:  "https://example.com/post",
+		},
+	}
+
+	cases := []struct {
+		name  string
+		write func(w *Writer) error
+	}{
+		{
+			name:  "list",
+			write: func(w *Writer) error { return w.List(posts, "No posts.") },
+		},
+		{
+			name:  "empty_list",
+			write: func(w *Writer) error { return w.List([]post(nil), "No posts.") },
+		},
+		{
+			name:  "item",
+			write: func(w *Writer) error { return w.Item(posts[0]) },
+		},
+		{
+			name: "result",
+			write: func(w *Writer) error {
+				return w.Result(struct {
+					Marked int `json:"marked"`
+				}{3}, "Marked %v posts read", 3)
+			},
+		},
+	}
+
+	for _, tt := range cases {
+		for _, format := range Formats {
+			t.Run(tt.name+"/"+string(format), func(t *testing.T) {
+				var out, log bytes.Buffer
+				w := New(&out, &log, format)
+				w.Status("progress")
+				if err := tt.write(w); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+
+				testutil.Golden(t, tt.name+"."+string(format)+".golden", out.Bytes())
+				// Progress stays out of JSON output.
+				if format == JSON && log.String() != "progress\n" {
+					t.Errorf("expected status on log, got: %q", log.String())
+				}
+			})
+		}
+	}
+}
+
+func TestListNeedsSlice(t *testing.T) {
+	err := New(&bytes.Buffer{}, &bytes.Buffer{}, Plain).List(post{}, "")
+	if err == nil {
+		t.Errorf("expected an error listing a struct")
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for _, format := range Formats {
+		got, err := ParseFormat(string(format))
+		if err != nil || got != format {
+			t.Errorf("expected format: %v, got: %v (%v)", format, got, err)
+		}
+	}
+	_, err := ParseFormat("yaml")
+	if !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("expected error: %v, got: %v", ErrUnknownFormat, err)
+	}
+}
@@ -0,0 +1,82 @@
+package retention
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Fraegdegjevar/Gator/internal/database"
+)
+
+var ErrNoPolicy = errors.New("no retention policy configured")
+
+// PrunedExpiry is how long pruned urls are remembered when the policy
+// has no MaxAge to go by.
+const PrunedExpiry = 30 * 24 * time.Hour
+
+// Policy decides which posts prune removes. A post is removed if it is
+// older than MaxAge or not among the newest MaxPerFeed posts of its feed
+// (zero disables either rule). Starred posts are always kept; with
+// KeepUnread, so are posts that anyone following the feed hasn't read.
+type Policy struct {
+	MaxAge     time.Duration
+	MaxPerFeed int
+	KeepUnread bool
+}
+
+func (p Policy) Enabled() bool {
+	return p.MaxAge > 0 || p.MaxPerFeed > 0
+}
+
+// Store is the subset of database.Queries pruning needs.
+type Store interface {
+	CountPrunablePosts(ctx context.Context, arg database.CountPrunablePostsParams) (int64, error)
+	PrunePosts(ctx context.Context, arg database.PrunePostsParams) (int64, error)
+	ExpirePrunedPosts(ctx context.Context, prunedBefore time.Time) (int64, error)
+}
+
+// Prune deletes the posts policy selects, as of now, returning how many
+// were removed. With dryRun nothing is deleted and the count is how
+// many would have been. The urls kept so agg doesn't store pruned posts
+// again are forgotten once they are older than MaxAge, or PrunedExpiry
+// without one.
+func Prune(ctx context.Context, store Store, policy Policy, now time.Time, dryRun bool) (int64, error) {
+	if !policy.Enabled() {
+		return 0, ErrNoPolicy
+	}
+
+	olderThan := sql.NullTime{}
+	if policy.MaxAge > 0 {
+		olderThan = sql.NullTime{Time: now.Add(-policy.MaxAge), Valid: true}
+	}
+	maxPerFeed := sql.NullInt64{}
+	if policy.MaxPerFeed > 0 {
+		maxPerFeed = sql.NullInt64{Int64: int64(policy.MaxPerFeed), Valid: true}
+	}
+
+	if dryRun {
+		return store.CountPrunablePosts(ctx, database.CountPrunablePostsParams{
+			OlderThan:  olderThan,
+			MaxPerFeed: maxPerFeed,
+			KeepUnread: policy.KeepUnread,
+		})
+	}
+	removed, err := store.PrunePosts(ctx, database.PrunePostsParams{
+		OlderThan:  olderThan,
+		MaxPerFeed: maxPerFeed,
+		KeepUnread: policy.KeepUnread,
+	})
+	if err != nil {
+		return 0, err
+	}
+	expiry := PrunedExpiry
+	if policy.MaxAge > 0 {
+		expiry = policy.MaxAge
+	}
+	_, err = store.ExpirePrunedPosts(ctx, now.Add(-expiry))
+	if err != nil {
+		return removed, err
+	}
+	return removed, nil
+}
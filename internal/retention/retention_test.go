@@ -0,0 +1,313 @@
+package retention
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Fraegdegjevar/Gator/internal/database"
+	"github.com/Fraegdegjevar/Gator/internal/testutil"
+	"github.com/google/uuid"
+)
+
+// fakeStore records the parameters it was last called with.
+type fakeStore struct {
+	counted *database.CountPrunablePostsParams
+	pruned  *database.PrunePostsParams
+	expired time.Time
+}
+
+func (f *fakeStore) CountPrunablePosts(ctx context.Context, arg database.CountPrunablePostsParams) (int64, error) {
+	f.counted = &arg
+	return 3, nil
+}
+
+func (f *fakeStore) PrunePosts(ctx context.Context, arg database.PrunePostsParams) (int64, error) {
+	f.pruned = &arg
+	return 5, nil
+}
+
+func (f *fakeStore) ExpirePrunedPosts(ctx context.Context, prunedBefore time.Time) (int64, error) {
+	f.expired = prunedBefore
+	return 2, nil
+}
+
+func TestPrune(t *testing.T) {
+	now := time.Date(2025, time.March, 31, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name               string
+		policy             Policy
+		dryRun             bool
+		expectedRemoved    int64
+		expectedOlderThan  sql.NullTime
+		expectedMaxPerFeed sql.NullInt64
+		expectedExpired    time.Time
+		expectedError      error
+	}{
+		{
+			name:          "no policy",
+			policy:        Policy{KeepUnread: true},
+			expectedError: ErrNoPolicy,
+		},
+		{
+			name:              "max age",
+			policy:            Policy{MaxAge: 30 * 24 * time.Hour},
+			expectedRemoved:   5,
+			expectedOlderThan: sql.NullTime{Time: now.Add(-30 * 24 * time.Hour), Valid: true},
+			expectedExpired:   now.Add(-30 * 24 * time.Hour),
+		},
+		{
+			name:               "max per feed",
+			policy:             Policy{MaxPerFeed: 100},
+			expectedRemoved:    5,
+			expectedMaxPerFeed: sql.NullInt64{Int64: 100, Valid: true},
+			expectedExpired:    now.Add(-PrunedExpiry),
+		},
+		{
+			name:              "max age dry run",
+			policy:            Policy{MaxAge: 30 * 24 * time.Hour},
+			dryRun:            true,
+			expectedRemoved:   3,
+			expectedOlderThan: sql.NullTime{Time: now.Add(-30 * 24 * time.Hour), Valid: true},
+		},
+		{
+			name:               "max per feed dry run",
+			policy:             Policy{MaxPerFeed: 100, KeepUnread: true},
+			dryRun:             true,
+			expectedRemoved:    3,
+			expectedMaxPerFeed: sql.NullInt64{Int64: 100, Valid: true},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &fakeStore{}
+			removed, err := Prune(context.Background(), store, tt.policy, now, tt.dryRun)
+			if !errors.Is(err, tt.expectedError) {
+				t.Fatalf("expected error: %v, got: %v", tt.expectedError, err)
+			}
+			if removed != tt.expectedRemoved {
+				t.Errorf("expected removed: %v, got: %v", tt.expectedRemoved, removed)
+			}
+			if tt.expectedError != nil {
+				if store.counted != nil || store.pruned != nil {
+					t.Errorf("expected no store calls without a policy")
+				}
+				return
+			}
+
+			var olderThan sql.NullTime
+			var maxPerFeed sql.NullInt64
+			var keepUnread bool
+			switch {
+			case tt.dryRun && store.counted != nil && store.pruned == nil:
+				olderThan, maxPerFeed, keepUnread = store.counted.OlderThan, store.counted.MaxPerFeed, store.counted.KeepUnread
+			case !tt.dryRun && store.pruned != nil && store.counted == nil:
+				olderThan, maxPerFeed, keepUnread = store.pruned.OlderThan, store.pruned.MaxPerFeed, store.pruned.KeepUnread
+			default:
+				t.Fatalf("expected dry run %v to call only the matching query, got count: %v, prune: %v", tt.dryRun, store.counted, store.pruned)
+			}
+			if olderThan != tt.expectedOlderThan {
+				t.Errorf("expected older than: %v, got: %v", tt.expectedOlderThan, olderThan)
+			}
+			if maxPerFeed != tt.expectedMaxPerFeed {
+				t.Errorf("expected max per feed: %v, got: %v", tt.expectedMaxPerFeed, maxPerFeed)
+			}
+			if keepUnread != tt.policy.KeepUnread {
+				t.Errorf("expected keep unread: %v, got: %v", tt.policy.KeepUnread, keepUnread)
+			}
+			if !store.expired.Equal(tt.expectedExpired) {
+				t.Errorf("expected pruned urls expired before: %v, got: %v", tt.expectedExpired, store.expired)
+			}
+		})
+	}
+}
+
+func TestPrunePosts(t *testing.T) {
+	tdb := testutil.DB(t)
+	now := time.Now()
+	day := 24 * time.Hour
+
+	// age is how long before now the post was published - zero leaves
+	// published_at empty so the post ranks by when it was added.
+	type post struct {
+		title   string
+		age     time.Duration
+		starred bool
+		read    bool
+		pruned  bool
+	}
+	cases := []struct {
+		name   string
+		policy Policy
+		posts  []post
+	}{
+		{
+			name:   "max age",
+			policy: Policy{MaxAge: 30 * day},
+			posts: []post{
+				{title: "old", age: 40 * day, pruned: true},
+				{title: "new", age: day},
+			},
+		},
+		{
+			name:   "max per feed ranks newest first",
+			policy: Policy{MaxPerFeed: 2},
+			posts: []post{
+				{title: "undated"},
+				{title: "newest", age: time.Hour},
+				{title: "older", age: 2 * time.Hour, pruned: true},
+				{title: "oldest", age: 3 * time.Hour, pruned: true},
+			},
+		},
+		{
+			name:   "starred posts kept",
+			policy: Policy{MaxAge: 30 * day},
+			posts: []post{
+				{title: "old starred", age: 40 * day, starred: true},
+				{title: "old", age: 40 * day, pruned: true},
+			},
+		},
+		{
+			name:   "keep unread",
+			policy: Policy{MaxAge: 30 * day, KeepUnread: true},
+			posts: []post{
+				{title: "old unread", age: 40 * day},
+				{title: "old read", age: 40 * day, read: true, pruned: true},
+			},
+		},
+		{
+			name:   "unread pruned without keep unread",
+			policy: Policy{MaxAge: 30 * day},
+			posts: []post{
+				{title: "old unread", age: 40 * day, pruned: true},
+				{title: "old read", age: 40 * day, read: true, pruned: true},
+			},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			// Prune looks at every post, so each case works in its own
+			// rolled back transaction and compares against what was
+			// prunable before it added anything.
+			tx := testutil.Tx(t, tdb)
+			q := database.New(tx)
+			before, err := Prune(ctx, q, tt.policy, now, true)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			user := testutil.User(t, tx)
+			feed := testutil.Feed(t, tx, user, "retention")
+			testutil.Follow(t, tx, user, feed)
+			created := make([]database.GetPostRow, len(tt.posts))
+			expectedPruned := int64(0)
+			for i, p := range tt.posts {
+				published := time.Time{}
+				if p.age > 0 {
+					published = now.Add(-p.age)
+				}
+				created[i] = testutil.Post(t, tx, feed, p.title, "", published)
+				if p.starred {
+					_, err := q.StarPost(ctx, database.StarPostParams{UserID: user.ID, PostID: created[i].ID, CreatedAt: now})
+					if err != nil {
+						t.Fatalf("error starring post: %v", err)
+					}
+				}
+				if p.read {
+					_, err := q.MarkPostRead(ctx, database.MarkPostReadParams{UserID: user.ID, PostID: created[i].ID})
+					if err != nil {
+						t.Fatalf("error marking post read: %v", err)
+					}
+				}
+				if p.pruned {
+					expectedPruned++
+				}
+			}
+
+			counted, err := Prune(ctx, q, tt.policy, now, true)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if counted-before != expectedPruned {
+				t.Errorf("expected prunable: %v, got: %v", expectedPruned, counted-before)
+			}
+			removed, err := Prune(ctx, q, tt.policy, now, false)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if removed != counted {
+				t.Errorf("expected removed: %v, got: %v", counted, removed)
+			}
+
+			for i, p := range tt.posts {
+				_, err := q.GetPost(ctx, created[i].ID)
+				if p.pruned && !errors.Is(err, sql.ErrNoRows) {
+					t.Errorf("expected %q to be pruned, got: %v", p.title, err)
+				}
+				if !p.pruned && err != nil {
+					t.Errorf("expected %q to be kept, got: %v", p.title, err)
+				}
+				if !p.pruned {
+					continue
+				}
+
+				// agg sees the pruned post in the feed again - it must
+				// not come back.
+				again := database.CreatePostParams{
+					ID:        uuid.New(),
+					CreatedAt: now,
+					UpdatedAt: now,
+					Title:     p.title,
+					Url:       created[i].Url,
+					FeedID:    feed.ID,
+				}
+				if err := q.CreatePost(ctx, again); err != nil {
+					t.Fatalf("error re-adding post: %v", err)
+				}
+				if _, err := q.GetPost(ctx, again.ID); !errors.Is(err, sql.ErrNoRows) {
+					t.Errorf("expected pruned %q not to be added again, got: %v", p.title, err)
+				}
+			}
+		})
+	}
+}
+
+func TestExpirePrunedPosts(t *testing.T) {
+	tdb := testutil.DB(t)
+	ctx := context.Background()
+	now := time.Now()
+	tx := testutil.Tx(t, tdb)
+	q := database.New(tx)
+
+	user := testutil.User(t, tx)
+	feed := testutil.Feed(t, tx, user, "retention")
+	old := testutil.Post(t, tx, feed, "old", "", now.Add(-40*24*time.Hour))
+	if _, err := Prune(ctx, q, Policy{MaxAge: 30 * 24 * time.Hour}, now, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Once the url has been forgotten agg may store the post again.
+	if _, err := q.ExpirePrunedPosts(ctx, now.Add(time.Minute)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	again := database.CreatePostParams{
+		ID:        uuid.New(),
+		CreatedAt: now,
+		UpdatedAt: now,
+		Title:     old.Title,
+		Url:       old.Url,
+		FeedID:    feed.ID,
+	}
+	if err := q.CreatePost(ctx, again); err != nil {
+		t.Fatalf("error re-adding post: %v", err)
+	}
+	if _, err := q.GetPost(ctx, again.ID); err != nil {
+		t.Errorf("expected expired url to be added again, got: %v", err)
+	}
+}
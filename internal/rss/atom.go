@@ -0,0 +1,116 @@
+package rss
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"time"
+)
+
+// AtomFeed mirrors the parts of an Atom 1.0 document Gator uses.
+type AtomFeed struct {
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle"`
+	Links    []AtomLink  `xml:"link"`
+	Entries  []AtomEntry `xml:"entry"`
+}
+
+type AtomEntry struct {
+	Title     string       `xml:"title"`
+	Links     []AtomLink   `xml:"link"`
+	Summary   AtomText     `xml:"summary"`
+	Content   AtomText     `xml:"content"`
+	Published string       `xml:"published"`
+	Updated   string       `xml:"updated"`
+	Authors   []AtomPerson `xml:"author"`
+}
+
+// AtomText is an Atom text construct. Text and html content is
+// character data; xhtml content is markup inside a wrapping <div>.
+type AtomText struct {
+	Type  string `xml:"type,attr"`
+	Text  string `xml:",chardata"`
+	XHTML struct {
+		Inner string `xml:",innerxml"`
+	} `xml:"http://www.w3.org/1999/xhtml div"`
+}
+
+// html returns the text as HTML. Text and html content is unescaped as
+// many feeds double-encode entities; xhtml content is already markup.
+func (t AtomText) html() string {
+	if t.Type == "xhtml" {
+		return strings.TrimSpace(t.XHTML.Inner)
+	}
+	return html.UnescapeString(t.Text)
+}
+
+type AtomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type AtomPerson struct {
+	Name string `xml:"name"`
+}
+
+func ParseAtom(data []byte) (*AtomFeed, error) {
+	feed := &AtomFeed{}
+	err := unmarshalXML(data, feed)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing Atom XML: %w", err)
+	}
+	return feed, nil
+}
+
+// alternateLink picks the link pointing at the HTML version of the
+// feed/entry. A link with no rel is an alternate link per RFC 4287.
+func alternateLink(links []AtomLink) string {
+	for _, link := range links {
+		if link.Rel == "" || link.Rel == "alternate" {
+			return link.Href
+		}
+	}
+	return ""
+}
+
+// Atom dates are RFC 3339.
+func parseAtomDate(s string) time.Time {
+	t, err := time.Parse(time.RFC3339, strings.TrimSpace(s))
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+func (f *AtomFeed) normalize() *Feed {
+	feed := &Feed{
+		Title:       html.UnescapeString(f.Title),
+		Link:        alternateLink(f.Links),
+		Description: html.UnescapeString(f.Subtitle),
+	}
+
+	for _, entry := range f.Entries {
+		description := entry.Summary.html()
+		if description == "" {
+			description = entry.Content.html()
+		}
+
+		authors := []string{}
+		for _, author := range entry.Authors {
+			if author.Name != "" {
+				authors = append(authors, author.Name)
+			}
+		}
+
+		feed.Items = append(feed.Items, Item{
+			Title:       html.UnescapeString(entry.Title),
+			Link:        alternateLink(entry.Links),
+			Description: description,
+			Published:   parseAtomDate(entry.Published),
+			Updated:     parseAtomDate(entry.Updated),
+			Authors:     authors,
+		})
+	}
+	return feed
+}
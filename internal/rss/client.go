@@ -0,0 +1,125 @@
+package rss
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const userAgent = "gator"
+
+// The most of a response body we read - a feed or page bigger than this
+// is a mistake or an attack, not something worth storing.
+const maxBodySize = 10 << 20
+
+var ErrBodyTooLarge = errors.New("response body too large")
+
+// Feed types we can parse, most specific first.
+const acceptHeader = "application/rss+xml, application/atom+xml, application/feed+json, application/xml;q=0.9, application/json;q=0.9, text/xml;q=0.8, */*;q=0.5"
+
+// Client fetches feeds over HTTP. Create one with NewClient and reuse it
+// so connections are pooled between fetches.
+type Client struct {
+	httpClient  *http.Client
+	maxBodySize int64
+}
+
+func NewClient(timeout time.Duration) *Client {
+	return &Client{
+		httpClient: &http.Client{
+			Timeout: timeout,
+		},
+		maxBodySize: maxBodySize,
+	}
+}
+
+// CacheValidators are the HTTP cache headers a server sent with the last
+// copy of a feed we downloaded. Sending them back lets the server answer
+// 304 Not Modified instead of the whole feed.
+type CacheValidators struct {
+	ETag         string
+	LastModified string
+}
+
+// FetchResult is the outcome of a successful fetch. If NotModified is
+// true the server said our cached copy is current and Feed is nil.
+// Validators should be stored and passed to the next fetch.
+type FetchResult struct {
+	Feed        *Feed
+	NotModified bool
+	Validators  CacheValidators
+}
+
+// FetchFeed downloads the feed at feedURL and parses it as RSS 2.0,
+// Atom 1.0 or JSON Feed 1.1 depending on what the server returns.
+// cached holds the validators from the previous fetch (zero value if
+// there wasn't one) and is used to make the request conditional.
+func (c *Client) FetchFeed(ctx context.Context, feedURL string, cached CacheValidators) (*FetchResult, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request for %v: %w", feedURL, err)
+	}
+	// Some hosts reject requests without a User-Agent, and it's polite
+	// to identify ourselves anyway.
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", acceptHeader)
+	if cached.ETag != "" {
+		req.Header.Set("If-None-Match", cached.ETag)
+	}
+	if cached.LastModified != "" {
+		req.Header.Set("If-Modified-Since", cached.LastModified)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching %v: %w", feedURL, err)
+	}
+	defer resp.Body.Close()
+
+	validators := CacheValidators{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+
+	if resp.StatusCode == http.StatusNotModified {
+		// A 304 may omit the validators - keep using the ones we have.
+		if validators.ETag == "" {
+			validators.ETag = cached.ETag
+		}
+		if validators.LastModified == "" {
+			validators.LastModified = cached.LastModified
+		}
+		return &FetchResult{NotModified: true, Validators: validators}, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error fetching %v: unexpected status %v", feedURL, resp.Status)
+	}
+
+	data, err := c.readBody(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response body from %v: %w", feedURL, err)
+	}
+
+	feed, err := Parse(data, resp.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing feed from %v: %w", feedURL, err)
+	}
+	return &FetchResult{Feed: feed, Validators: validators}, nil
+}
+
+// readBody reads all of body, failing with ErrBodyTooLarge rather than
+// reading on past the client's limit.
+func (c *Client) readBody(body io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(body, c.maxBodySize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > c.maxBodySize {
+		return nil, fmt.Errorf("%w: over %v bytes", ErrBodyTooLarge, c.maxBodySize)
+	}
+	return data, nil
+}
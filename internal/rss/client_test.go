@@ -0,0 +1,162 @@
+package rss
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestFetchFeedConditional(t *testing.T) {
+	const etag = `"v1"`
+	const lastModified = "Mon, 06 Jan 2025 10:00:00 GMT"
+
+	// Write the fixture ourselves - http.ServeFile would add its own
+	// Last-Modified from the file's mtime.
+	fixture, err := os.ReadFile("testdata/rss.xml")
+	if err != nil {
+		t.Fatalf("error reading fixture: %v", err)
+	}
+
+	// Stand-in feed server that honours the conditional headers the way
+	// a real one would. /etag-only uses just ETag, /304-no-headers sends
+	// a bare 304 with no validators.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/feed":
+			w.Header().Set("ETag", etag)
+			w.Header().Set("Last-Modified", lastModified)
+			if r.Header.Get("If-None-Match") == etag || r.Header.Get("If-Modified-Since") == lastModified {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		case "/etag-only":
+			w.Header().Set("ETag", etag)
+			if r.Header.Get("If-None-Match") == etag {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		case "/304-no-headers":
+			if r.Header.Get("If-None-Match") != "" {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		}
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write(fixture)
+	}))
+	defer server.Close()
+
+	cases := []struct {
+		name                string
+		path                string
+		cached              CacheValidators
+		expectedNotModified bool
+		expectedValidators  CacheValidators
+	}{
+		{
+			name:                "first fetch gets validators",
+			path:                "/feed",
+			cached:              CacheValidators{},
+			expectedNotModified: false,
+			expectedValidators:  CacheValidators{ETag: etag, LastModified: lastModified},
+		},
+		{
+			name:                "matching etag is not modified",
+			path:                "/feed",
+			cached:              CacheValidators{ETag: etag},
+			expectedNotModified: true,
+			expectedValidators:  CacheValidators{ETag: etag, LastModified: lastModified},
+		},
+		{
+			name:                "matching last modified is not modified",
+			path:                "/feed",
+			cached:              CacheValidators{LastModified: lastModified},
+			expectedNotModified: true,
+			expectedValidators:  CacheValidators{ETag: etag, LastModified: lastModified},
+		},
+		{
+			name:                "stale etag downloads again",
+			path:                "/etag-only",
+			cached:              CacheValidators{ETag: `"v0"`},
+			expectedNotModified: false,
+			expectedValidators:  CacheValidators{ETag: etag},
+		},
+		{
+			name:                "304 without headers keeps cached validators",
+			path:                "/304-no-headers",
+			cached:              CacheValidators{ETag: `"old"`, LastModified: lastModified},
+			expectedNotModified: true,
+			expectedValidators:  CacheValidators{ETag: `"old"`, LastModified: lastModified},
+		},
+	}
+
+	client := NewClient(5 * time.Second)
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := client.FetchFeed(context.Background(), server.URL+tt.path, tt.cached)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.NotModified != tt.expectedNotModified {
+				t.Errorf("expected not modified: %v, got: %v", tt.expectedNotModified, result.NotModified)
+			}
+			if result.NotModified && result.Feed != nil {
+				t.Errorf("expected no feed with a 304, got: %v", result.Feed)
+			}
+			if !result.NotModified && result.Feed == nil {
+				t.Errorf("expected a parsed feed, got nil")
+			}
+			if result.Validators != tt.expectedValidators {
+				t.Errorf("expected validators: %+v, got: %+v", tt.expectedValidators, result.Validators)
+			}
+		})
+	}
+}
+
+func TestBodyLimit(t *testing.T) {
+	fixture, err := os.ReadFile("testdata/rss.xml")
+	if err != nil {
+		t.Fatalf("error reading fixture: %v", err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write(fixture)
+	}))
+	defer server.Close()
+
+	cases := []struct {
+		name          string
+		maxBodySize   int64
+		expectedError error
+	}{
+		{
+			name:        "within limit",
+			maxBodySize: int64(len(fixture)),
+		},
+		{
+			name:          "over limit",
+			maxBodySize:   int64(len(fixture)) - 1,
+			expectedError: ErrBodyTooLarge,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient(5 * time.Second)
+			client.maxBodySize = tt.maxBodySize
+
+			_, err := client.FetchFeed(context.Background(), server.URL, CacheValidators{})
+			if !errors.Is(err, tt.expectedError) {
+				t.Errorf("expected fetch error: %v, got: %v", tt.expectedError, err)
+			}
+			_, err = client.Discover(context.Background(), server.URL)
+			if !errors.Is(err, tt.expectedError) {
+				t.Errorf("expected discover error: %v, got: %v", tt.expectedError, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package rss
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+var ErrNoFeedsFound = errors.New("no feeds found")
+
+// Link types that mark an alternate link as a feed.
+var feedLinkTypes = map[string]bool{
+	"application/rss+xml":   true,
+	"application/atom+xml":  true,
+	"application/feed+json": true,
+}
+
+// Where sites commonly put their feed, tried in order if a page has no
+// feed <link> tags.
+var commonFeedPaths = []string{
+	"/feed",
+	"/rss",
+	"/rss.xml",
+	"/atom.xml",
+	"/feed.xml",
+	"/index.xml",
+	"/feed.json",
+}
+
+// Candidate is a feed found while discovering feeds from a url.
+type Candidate struct {
+	URL   string
+	Title string
+}
+
+// Discover works out which feed(s) pageURL refers to. If pageURL is
+// itself a feed it is the only candidate. If it is an HTML page, its
+// <link rel="alternate"> feed links are returned, falling back to
+// probing common feed paths on the same site.
+func (c *Client) Discover(ctx context.Context, pageURL string) ([]Candidate, error) {
+	data, contentType, finalURL, err := c.get(ctx, pageURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isHTML(data, contentType) {
+		feed, err := Parse(data, contentType)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing feed from %v: %w", pageURL, err)
+		}
+		return []Candidate{{URL: pageURL, Title: feed.Title}}, nil
+	}
+
+	candidates := FeedLinks(data, finalURL)
+	if len(candidates) > 0 {
+		return candidates, nil
+	}
+
+	for _, path := range commonFeedPaths {
+		probe := finalURL.ResolveReference(&url.URL{Path: path})
+		data, contentType, _, err := c.get(ctx, probe.String())
+		if err != nil || isHTML(data, contentType) {
+			continue
+		}
+		feed, err := Parse(data, contentType)
+		if err != nil {
+			continue
+		}
+		return []Candidate{{URL: probe.String(), Title: feed.Title}}, nil
+	}
+
+	return nil, fmt.Errorf("%w at %v", ErrNoFeedsFound, pageURL)
+}
+
+// get downloads rawURL, returning the body, its content type and the
+// url it was finally served from after redirects.
+func (c *Client) get(ctx context.Context, rawURL string) ([]byte, string, *url.URL, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("error creating request for %v: %w", rawURL, err)
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("error fetching %v: %w", rawURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", nil, fmt.Errorf("error fetching %v: unexpected status %v", rawURL, resp.Status)
+	}
+
+	data, err := c.readBody(resp.Body)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("error reading response body from %v: %w", rawURL, err)
+	}
+	return data, resp.Header.Get("Content-Type"), resp.Request.URL, nil
+}
+
+func isHTML(data []byte, contentType string) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType == "text/html" || mediaType == "application/xhtml+xml" {
+		return true
+	}
+	if mediaType != "" {
+		return false
+	}
+	// No header - sniff the body instead.
+	trimmed := bytes.ToLower(bytes.TrimSpace(data))
+	return bytes.HasPrefix(trimmed, []byte("<!doctype html")) || bytes.HasPrefix(trimmed, []byte("<html"))
+}
+
+// FeedLinks returns the feeds advertised by an HTML page's
+// <link rel="alternate" type="application/rss+xml"> (or Atom/JSON Feed)
+// tags, resolved against base (or the page's <base href>, if any).
+// Duplicates are dropped.
+func FeedLinks(page []byte, base *url.URL) []Candidate {
+	candidates := []Candidate{}
+	seen := make(map[string]bool)
+
+	tokenizer := html.NewTokenizer(bytes.NewReader(page))
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == html.ErrorToken {
+			return candidates
+		}
+		if tokenType != html.StartTagToken && tokenType != html.SelfClosingTagToken {
+			continue
+		}
+
+		token := tokenizer.Token()
+		attrs := make(map[string]string)
+		for _, attr := range token.Attr {
+			attrs[strings.ToLower(attr.Key)] = strings.TrimSpace(attr.Val)
+		}
+
+		switch token.Data {
+		case "base":
+			if href, err := url.Parse(attrs["href"]); err == nil && attrs["href"] != "" {
+				base = base.ResolveReference(href)
+			}
+		case "link":
+			if !hasToken(attrs["rel"], "alternate") || !feedLinkTypes[strings.ToLower(attrs["type"])] {
+				continue
+			}
+			href, err := url.Parse(attrs["href"])
+			if err != nil || attrs["href"] == "" {
+				continue
+			}
+			resolved := base.ResolveReference(href).String()
+			if seen[resolved] {
+				continue
+			}
+			seen[resolved] = true
+			candidates = append(candidates, Candidate{URL: resolved, Title: attrs["title"]})
+		case "body":
+			// Feed links live in <head>; no need to read the whole page.
+			return candidates
+		}
+	}
+}
+
+// rel is a space separated list of link types.
+func hasToken(list, token string) bool {
+	for _, t := range strings.Fields(strings.ToLower(list)) {
+		if t == token {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,157 @@
+package rss
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFeedLinks(t *testing.T) {
+	page, err := os.ReadFile("testdata/blog.html")
+	if err != nil {
+		t.Fatalf("error reading fixture: %v", err)
+	}
+
+	cases := []struct {
+		name               string
+		page               string
+		base               string
+		expectedCandidates []Candidate
+	}{
+		{
+			name: "relative, absolute and duplicate links",
+			page: string(page),
+			base: "https://blog.example.com/",
+			expectedCandidates: []Candidate{
+				{URL: "https://blog.example.com/posts/rss.xml", Title: "Posts (RSS)"},
+				{URL: "https://feeds.example.net/atom", Title: "Posts (Atom)"},
+			},
+		},
+		{
+			name: "base href",
+			page: `<html><head><base href="https://cdn.example.com/blog/"><link rel="alternate feed" type="application/feed+json" href="feed.json"></head></html>`,
+			base: "https://example.com/",
+			expectedCandidates: []Candidate{
+				{URL: "https://cdn.example.com/blog/feed.json"},
+			},
+		},
+		{
+			name:               "no feed links",
+			page:               `<html><head><link rel="icon" href="/favicon.ico"></head></html>`,
+			base:               "https://example.com/",
+			expectedCandidates: []Candidate{},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			base, err := url.Parse(tt.base)
+			if err != nil {
+				t.Fatalf("error parsing base url: %v", err)
+			}
+			got := FeedLinks([]byte(tt.page), base)
+			if !reflect.DeepEqual(tt.expectedCandidates, got) {
+				t.Errorf("expected candidates: %+v, got: %+v", tt.expectedCandidates, got)
+			}
+		})
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	mux := http.NewServeMux()
+	// A blog advertising two feeds.
+	mux.HandleFunc("/blog/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(`<html><head>
+			<link rel="alternate" type="application/rss+xml" title="RSS" href="rss.xml">
+			<link rel="alternate" type="application/atom+xml" title="Atom" href="/blog/atom.xml">
+		</head></html>`))
+	})
+	// A site with no feed links, but a feed at a well known path.
+	mux.HandleFunc("/plain/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/plain/" {
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte(`<html><head><title>Plain</title></head></html>`))
+			return
+		}
+		http.NotFound(w, r)
+	})
+	mux.HandleFunc("/feed.xml", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		http.ServeFile(w, r, "testdata/rss.xml")
+	})
+	// Anything else is an HTML page, so probing /feed, /rss etc. must
+	// skip them until it reaches /feed.xml.
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><body>nothing here</body></html>`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	cases := []struct {
+		name               string
+		path               string
+		expectedCandidates []Candidate
+		expectedError      error
+	}{
+		{
+			name: "html page with feed links",
+			path: "/blog/",
+			expectedCandidates: []Candidate{
+				{URL: server.URL + "/blog/rss.xml", Title: "RSS"},
+				{URL: server.URL + "/blog/atom.xml", Title: "Atom"},
+			},
+		},
+		{
+			name: "url is already a feed",
+			path: "/feed.xml",
+			expectedCandidates: []Candidate{
+				{URL: server.URL + "/feed.xml", Title: "Test & Feed"},
+			},
+		},
+		{
+			name: "fallback to common path",
+			path: "/plain/",
+			expectedCandidates: []Candidate{
+				{URL: server.URL + "/feed.xml", Title: "Test & Feed"},
+			},
+		},
+	}
+
+	client := NewClient(5 * time.Second)
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := client.Discover(context.Background(), server.URL+tt.path)
+			if !errors.Is(err, tt.expectedError) {
+				t.Fatalf("expected error: %v, got: %v", tt.expectedError, err)
+			}
+			if !reflect.DeepEqual(tt.expectedCandidates, got) {
+				t.Errorf("expected candidates: %+v, got: %+v", tt.expectedCandidates, got)
+			}
+		})
+	}
+}
+
+func TestDiscoverNoFeeds(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><head><title>No feeds</title></head></html>`))
+	}))
+	defer server.Close()
+
+	_, err := NewClient(5*time.Second).Discover(context.Background(), server.URL)
+	if !errors.Is(err, ErrNoFeedsFound) {
+		t.Errorf("expected error: %v, got: %v", ErrNoFeedsFound, err)
+	}
+}
@@ -0,0 +1,117 @@
+package rss
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html/charset"
+)
+
+var ErrUnknownFormat = errors.New("unrecognised feed format")
+
+// Feed is the format-independent view of a feed. RSS, Atom and JSON
+// Feed documents are all normalised into this so the rest of Gator
+// doesn't care which one a site publishes.
+type Feed struct {
+	Title       string
+	Link        string
+	Description string
+	Items       []Item
+}
+
+// Item maps onto a row in the posts table. Published and Updated are
+// the zero time if the feed didn't supply (or we couldn't parse) them.
+type Item struct {
+	Title       string
+	Link        string
+	Description string
+	Published   time.Time
+	Updated     time.Time
+	Authors     []string
+}
+
+// PublishedOrUpdated returns the best date we have for the item - many
+// Atom feeds only supply <updated>.
+func (i Item) PublishedOrUpdated() time.Time {
+	if !i.Published.IsZero() {
+		return i.Published
+	}
+	return i.Updated
+}
+
+// Parse detects the format of data from contentType (the HTTP
+// Content-Type header, may be empty) and the document itself, then
+// parses and normalises it.
+func Parse(data []byte, contentType string) (*Feed, error) {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	trimmed := bytes.TrimSpace(data)
+
+	// Servers often send JSON Feed as plain application/json or even
+	// text/plain, so sniffing the first byte is more reliable than the
+	// header alone.
+	if strings.HasSuffix(mediaType, "json") || bytes.HasPrefix(trimmed, []byte("{")) {
+		jsonFeed, err := ParseJSONFeed(data)
+		if err != nil {
+			return nil, err
+		}
+		return jsonFeed.normalize(), nil
+	}
+
+	root, err := rootElement(data)
+	if err != nil {
+		return nil, err
+	}
+
+	switch root {
+	case "rss":
+		rssFeed, err := ParseRSS(data)
+		if err != nil {
+			return nil, err
+		}
+		return rssFeed.normalize(), nil
+	case "feed":
+		atomFeed, err := ParseAtom(data)
+		if err != nil {
+			return nil, err
+		}
+		return atomFeed.normalize(), nil
+	default:
+		return nil, fmt.Errorf("%w: root element <%v>", ErrUnknownFormat, root)
+	}
+}
+
+// unmarshalXML is xml.Unmarshal for feeds, which often declare a
+// charset other than UTF-8 (ISO-8859-1 and windows-1252 are common).
+func unmarshalXML(data []byte, v any) error {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.CharsetReader = charset.NewReaderLabel
+	return decoder.Decode(v)
+}
+
+// rootElement returns the local name of the first element in an XML
+// document, skipping the prolog, comments and so on.
+func rootElement(data []byte) (string, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	// We only want the element name, so don't fail on unusual charsets.
+	decoder.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return "", ErrUnknownFormat
+		}
+		if err != nil {
+			return "", fmt.Errorf("%w: %w", ErrUnknownFormat, err)
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name.Local, nil
+		}
+	}
+}
@@ -0,0 +1,271 @@
+package rss
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	est := time.FixedZone("", -5*60*60)
+	cet := time.FixedZone("", 60*60)
+
+	cases := []struct {
+		name          string
+		fixture       string
+		contentType   string
+		expectedTitle string
+		expectedLink  string
+		expectedItems []Item
+	}{
+		{
+			name:          "rss",
+			fixture:       "testdata/rss.xml",
+			contentType:   "application/rss+xml; charset=utf-8",
+			expectedTitle: "Test & Feed",
+			expectedLink:  "https://example.com",
+			expectedItems: []Item{
+				{
+					Title:       "First post",
+					Link:        "https://example.com/first",
+					Description: "<p>hello</p>",
+					Published:   time.Date(2025, time.January, 6, 10, 0, 0, 0, time.UTC),
+					Authors:     []string{"Jane Doe"},
+				},
+				{
+					Title:   "Second post",
+					Link:    "https://example.com/second",
+					Authors: []string{},
+				},
+			},
+		},
+		{
+			name:          "rss in latin-1",
+			fixture:       "testdata/rss-latin1.xml",
+			contentType:   "application/rss+xml",
+			expectedTitle: "Café crème",
+			expectedLink:  "https://example.fr",
+			expectedItems: []Item{
+				{
+					Title:       "Déjà vu",
+					Link:        "https://example.fr/deja-vu",
+					Description: "Crêpes à volonté",
+					Published:   time.Date(2025, time.January, 6, 9, 0, 0, 0, time.UTC),
+					Authors:     []string{},
+				},
+			},
+		},
+		{
+			name:          "atom",
+			fixture:       "testdata/atom.xml",
+			contentType:   "application/atom+xml",
+			expectedTitle: "Release notes from gator",
+			expectedLink:  "https://example.com/releases",
+			expectedItems: []Item{
+				{
+					Title:       "v1.1.0",
+					Link:        "https://example.com/releases/v1.1.0",
+					Description: "Bug fixes & improvements",
+					Published:   time.Date(2025, time.February, 1, 12, 0, 0, 0, time.UTC),
+					Updated:     time.Date(2025, time.February, 2, 8, 30, 0, 0, cet),
+					Authors:     []string{"Alice", "Bob"},
+				},
+				{
+					Title:       "v1.0.0",
+					Link:        "https://example.com/releases/v1.0.0",
+					Description: "First stable release",
+					Updated:     time.Date(2025, time.January, 15, 9, 0, 0, 0, time.UTC),
+					Authors:     []string{},
+				},
+				{
+					Title:       "v0.9.0",
+					Link:        "https://example.com/releases/v0.9.0",
+					Description: "<p>Beta with <b>xhtml</b> notes &amp; more</p>",
+					Updated:     time.Date(2025, time.January, 1, 9, 0, 0, 0, time.UTC),
+					Authors:     []string{},
+				},
+			},
+		},
+		{
+			name:          "atom with generic content type",
+			fixture:       "testdata/atom.xml",
+			contentType:   "text/xml",
+			expectedTitle: "Release notes from gator",
+			expectedLink:  "https://example.com/releases",
+		},
+		{
+			name:          "json feed",
+			fixture:       "testdata/jsonfeed.json",
+			contentType:   "application/feed+json",
+			expectedTitle: "JSON Blog",
+			expectedLink:  "https://example.org/",
+			expectedItems: []Item{
+				{
+					Title:       "Second entry",
+					Link:        "https://example.org/second",
+					Description: "<p>Hello from JSON</p>",
+					Published:   time.Date(2025, time.March, 10, 18, 0, 0, 0, est),
+					Authors:     []string{"Carol"},
+				},
+				{
+					Title:       "Linked entry",
+					Link:        "https://elsewhere.example/linked",
+					Description: "plain text body",
+					Updated:     time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC),
+					Authors:     []string{"Dave"},
+				},
+			},
+		},
+		{
+			name:          "json feed without content type",
+			fixture:       "testdata/jsonfeed.json",
+			contentType:   "",
+			expectedTitle: "JSON Blog",
+			expectedLink:  "https://example.org/",
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := os.ReadFile(tt.fixture)
+			if err != nil {
+				t.Fatalf("error reading fixture: %v", err)
+			}
+
+			feed, err := Parse(data, tt.contentType)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if feed.Title != tt.expectedTitle {
+				t.Errorf("expected title: %q, got: %q", tt.expectedTitle, feed.Title)
+			}
+			if feed.Link != tt.expectedLink {
+				t.Errorf("expected link: %q, got: %q", tt.expectedLink, feed.Link)
+			}
+			if tt.expectedItems == nil {
+				return
+			}
+			if len(feed.Items) != len(tt.expectedItems) {
+				t.Fatalf("expected %v items, got: %v", len(tt.expectedItems), len(feed.Items))
+			}
+			for i, expected := range tt.expectedItems {
+				got := feed.Items[i]
+				// Compare times with Equal - time zones are parsed into
+				// distinct *Location values.
+				if !got.Published.Equal(expected.Published) || !got.Updated.Equal(expected.Updated) {
+					t.Errorf("item %v: expected published/updated: %v/%v, got: %v/%v",
+						i, expected.Published, expected.Updated, got.Published, got.Updated)
+				}
+				got.Published, got.Updated = expected.Published, expected.Updated
+				if !reflect.DeepEqual(expected, got) {
+					t.Errorf("item %v: expected: %+v, got: %+v", i, expected, got)
+				}
+			}
+		})
+	}
+}
+
+func TestParseUnknownFormat(t *testing.T) {
+	cases := []struct {
+		name string
+		data string
+	}{
+		{name: "html", data: "<!DOCTYPE html><html><body>hi</body></html>"},
+		{name: "empty", data: ""},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.data), "text/html")
+			if !errors.Is(err, ErrUnknownFormat) {
+				t.Errorf("expected error: %v, got: %v", ErrUnknownFormat, err)
+			}
+		})
+	}
+}
+
+func TestPublishedOrUpdated(t *testing.T) {
+	published := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	updated := time.Date(2025, time.January, 2, 0, 0, 0, 0, time.UTC)
+
+	if got := (Item{Published: published, Updated: updated}).PublishedOrUpdated(); !got.Equal(published) {
+		t.Errorf("expected published date: %v, got: %v", published, got)
+	}
+	if got := (Item{Updated: updated}).PublishedOrUpdated(); !got.Equal(updated) {
+		t.Errorf("expected fallback to updated date: %v, got: %v", updated, got)
+	}
+}
+
+func TestFetchFeed(t *testing.T) {
+	var gotUserAgent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUserAgent = r.Header.Get("User-Agent")
+		switch r.URL.Path {
+		case "/rss":
+			w.Header().Set("Content-Type", "application/rss+xml")
+			http.ServeFile(w, r, "testdata/rss.xml")
+		case "/atom":
+			w.Header().Set("Content-Type", "application/atom+xml")
+			http.ServeFile(w, r, "testdata/atom.xml")
+		case "/json":
+			w.Header().Set("Content-Type", "application/feed+json")
+			http.ServeFile(w, r, "testdata/jsonfeed.json")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	cases := []struct {
+		name          string
+		path          string
+		expectedItems int
+		expectedErr   bool
+	}{
+		{
+			name:          "rss",
+			path:          "/rss",
+			expectedItems: 2,
+		},
+		{
+			name:          "atom",
+			path:          "/atom",
+			expectedItems: 3,
+		},
+		{
+			name:          "json feed",
+			path:          "/json",
+			expectedItems: 2,
+		},
+		{
+			name:        "not found",
+			path:        "/missing",
+			expectedErr: true,
+		},
+	}
+
+	client := NewClient(5 * time.Second)
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := client.FetchFeed(context.Background(), server.URL+tt.path, CacheValidators{})
+			if (err != nil) != tt.expectedErr {
+				t.Fatalf("expected error: %v but got: %v", tt.expectedErr, err)
+			}
+			if gotUserAgent != userAgent {
+				t.Errorf("expected User-Agent: %q, got: %q", userAgent, gotUserAgent)
+			}
+			if err != nil {
+				return
+			}
+			if len(result.Feed.Items) != tt.expectedItems {
+				t.Errorf("expected %v items, got: %v", tt.expectedItems, len(result.Feed.Items))
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package rss
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// JSONFeed mirrors a JSON Feed 1.1 document (https://jsonfeed.org).
+type JSONFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url"`
+	Description string         `json:"description"`
+	Items       []JSONFeedItem `json:"items"`
+}
+
+type JSONFeedItem struct {
+	ID            string `json:"id"`
+	URL           string `json:"url"`
+	ExternalURL   string `json:"external_url"`
+	Title         string `json:"title"`
+	ContentHTML   string `json:"content_html"`
+	ContentText   string `json:"content_text"`
+	Summary       string `json:"summary"`
+	DatePublished string `json:"date_published"`
+	DateModified  string `json:"date_modified"`
+	// 1.1 uses authors; author is the deprecated 1.0 field but is still
+	// common.
+	Authors []JSONFeedAuthor `json:"authors"`
+	Author  *JSONFeedAuthor  `json:"author"`
+}
+
+type JSONFeedAuthor struct {
+	Name string `json:"name"`
+}
+
+func ParseJSONFeed(data []byte) (*JSONFeed, error) {
+	feed := &JSONFeed{}
+	err := json.Unmarshal(data, feed)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing JSON Feed: %w", err)
+	}
+	return feed, nil
+}
+
+func (f *JSONFeed) normalize() *Feed {
+	feed := &Feed{
+		Title:       f.Title,
+		Link:        f.HomePageURL,
+		Description: f.Description,
+	}
+
+	for _, item := range f.Items {
+		link := item.URL
+		if link == "" {
+			link = item.ExternalURL
+		}
+
+		description := item.Summary
+		if description == "" {
+			description = item.ContentHTML
+		}
+		if description == "" {
+			description = item.ContentText
+		}
+
+		authors := []string{}
+		if item.Author != nil && item.Author.Name != "" {
+			authors = append(authors, item.Author.Name)
+		}
+		for _, author := range item.Authors {
+			if author.Name != "" {
+				authors = append(authors, author.Name)
+			}
+		}
+
+		feed.Items = append(feed.Items, Item{
+			Title:       item.Title,
+			Link:        link,
+			Description: description,
+			// JSON Feed dates are RFC 3339, same as Atom.
+			Published: parseAtomDate(item.DatePublished),
+			Updated:   parseAtomDate(item.DateModified),
+			Authors:   authors,
+		})
+	}
+	return feed
+}
@@ -0,0 +1,99 @@
+package rss
+
+import (
+	"fmt"
+	"html"
+	"time"
+)
+
+// RSSFeed mirrors the structure of an RSS 2.0 document. Only the fields
+// Gator stores are mapped - encoding/xml ignores everything else.
+type RSSFeed struct {
+	Channel struct {
+		Title       string    `xml:"title"`
+		Link        string    `xml:"link"`
+		Description string    `xml:"description"`
+		Item        []RSSItem `xml:"item"`
+	} `xml:"channel"`
+}
+
+type RSSItem struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	Description string `xml:"description"`
+	PubDate     string `xml:"pubDate"`
+	// <author> is meant to be an email address, so most feeds use the
+	// Dublin Core <dc:creator> instead.
+	Author  string `xml:"author"`
+	Creator string `xml:"http://purl.org/dc/elements/1.1/ creator"`
+}
+
+// pubDate is meant to be RFC 822 but feeds in the wild use all sorts of
+// variations, so try the common ones in turn.
+var pubDateLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	time.RFC822Z,
+	time.RFC822,
+	time.RFC3339,
+}
+
+// ParseRSS unmarshals raw RSS 2.0 XML. Titles and descriptions are
+// HTML-unescaped as many feeds double-encode entities.
+func ParseRSS(data []byte) (*RSSFeed, error) {
+	feed := &RSSFeed{}
+	err := unmarshalXML(data, feed)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing RSS XML: %w", err)
+	}
+
+	feed.Channel.Title = html.UnescapeString(feed.Channel.Title)
+	feed.Channel.Description = html.UnescapeString(feed.Channel.Description)
+	for i := range feed.Channel.Item {
+		feed.Channel.Item[i].Title = html.UnescapeString(feed.Channel.Item[i].Title)
+		feed.Channel.Item[i].Description = html.UnescapeString(feed.Channel.Item[i].Description)
+	}
+	return feed, nil
+}
+
+// PublishedAt parses the item's pubDate. ok is false if the date is
+// missing or in a format we don't recognise.
+func (i RSSItem) PublishedAt() (t time.Time, ok bool) {
+	for _, layout := range pubDateLayouts {
+		t, err := time.Parse(layout, i.PubDate)
+		if err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func (f *RSSFeed) normalize() *Feed {
+	feed := &Feed{
+		Title:       f.Channel.Title,
+		Link:        f.Channel.Link,
+		Description: f.Channel.Description,
+	}
+
+	for _, item := range f.Channel.Item {
+		authors := []string{}
+		if item.Creator != "" {
+			authors = append(authors, item.Creator)
+		} else if item.Author != "" {
+			authors = append(authors, item.Author)
+		}
+
+		published, _ := item.PublishedAt()
+		feed.Items = append(feed.Items, Item{
+			Title:       item.Title,
+			Link:        item.Link,
+			Description: item.Description,
+			Published:   published,
+			Authors:     authors,
+		})
+	}
+	return feed
+}
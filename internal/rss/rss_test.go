@@ -0,0 +1,46 @@
+package rss
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestParseRSS(t *testing.T) {
+	data, err := os.ReadFile("testdata/rss.xml")
+	if err != nil {
+		t.Fatalf("error reading fixture: %v", err)
+	}
+
+	feed, err := ParseRSS(data)
+	if err != nil {
+		t.Fatalf("unexpected error parsing RSS: %v", err)
+	}
+
+	if feed.Channel.Title != "Test & Feed" {
+		t.Errorf("expected unescaped title: %q, got: %q", "Test & Feed", feed.Channel.Title)
+	}
+	if len(feed.Channel.Item) != 2 {
+		t.Fatalf("expected 2 items, got: %v", len(feed.Channel.Item))
+	}
+	if feed.Channel.Item[0].Description != "<p>hello</p>" {
+		t.Errorf("expected description: %q, got: %q", "<p>hello</p>", feed.Channel.Item[0].Description)
+	}
+
+	published, ok := feed.Channel.Item[0].PublishedAt()
+	expected := time.Date(2025, time.January, 6, 10, 0, 0, 0, time.UTC)
+	if !ok || !published.Equal(expected) {
+		t.Errorf("expected published at: %v, got: %v (ok: %v)", expected, published, ok)
+	}
+
+	if _, ok := feed.Channel.Item[1].PublishedAt(); ok {
+		t.Errorf("expected unparseable pubDate to report ok == false")
+	}
+}
+
+func TestParseRSSInvalid(t *testing.T) {
+	_, err := ParseRSS([]byte("<rss><channel>"))
+	if err == nil {
+		t.Errorf("expected error parsing truncated XML, got nil")
+	}
+}
@@ -0,0 +1,110 @@
+package scraper
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// tokenBucket allows rate requests per second on average, with bursts
+// of up to burst requests.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	return &tokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// wait blocks until a token is available or ctx is done.
+func (b *tokenBucket) wait(ctx context.Context) error {
+	for {
+		b.mu.Lock()
+		now := time.Now()
+		b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+		b.last = now
+		if b.tokens >= 1 {
+			b.tokens--
+			b.mu.Unlock()
+			return nil
+		}
+		// Sleep roughly until the next token is due, then re-check - another
+		// waiter may have beaten us to it.
+		delay := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+		b.mu.Unlock()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// hostLimiter caps how many requests can be in flight to one host and
+// how often new ones may start, so a user following lots of feeds on
+// the same site doesn't hammer it.
+type hostLimiter struct {
+	concurrency int
+	rate        float64
+
+	mu    sync.Mutex
+	hosts map[string]*hostSlot
+}
+
+type hostSlot struct {
+	sem    chan struct{}
+	bucket *tokenBucket
+}
+
+func newHostLimiter(concurrency int, rate float64) *hostLimiter {
+	return &hostLimiter{
+		concurrency: concurrency,
+		rate:        rate,
+		hosts:       make(map[string]*hostSlot),
+	}
+}
+
+func (l *hostLimiter) slot(host string) *hostSlot {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	slot, ok := l.hosts[host]
+	if !ok {
+		slot = &hostSlot{
+			sem:    make(chan struct{}, l.concurrency),
+			bucket: newTokenBucket(l.rate, l.concurrency),
+		}
+		l.hosts[host] = slot
+	}
+	return slot
+}
+
+// acquire waits for permission to make a request to host. On success
+// the caller must call release once the request is done.
+func (l *hostLimiter) acquire(ctx context.Context, host string) (release func(), err error) {
+	slot := l.slot(host)
+
+	select {
+	case slot.sem <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	err = slot.bucket.wait(ctx)
+	if err != nil {
+		<-slot.sem
+		return nil, err
+	}
+	return func() { <-slot.sem }, nil
+}
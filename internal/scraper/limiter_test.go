@@ -0,0 +1,89 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTokenBucket(t *testing.T) {
+	// 20 per second with a burst of 2: the first 2 are immediate, the
+	// next 2 need ~50ms each.
+	bucket := newTokenBucket(20, 2)
+	start := time.Now()
+	for range 4 {
+		err := bucket.wait(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	elapsed := time.Since(start)
+	if elapsed < 80*time.Millisecond {
+		t.Errorf("expected rate limiting to take at least 80ms, took: %v", elapsed)
+	}
+}
+
+func TestTokenBucketCancel(t *testing.T) {
+	bucket := newTokenBucket(0.001, 1)
+	err := bucket.wait(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error taking burst token: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err = bucket.wait(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected error: %v, got: %v", context.DeadlineExceeded, err)
+	}
+}
+
+func TestHostLimiterConcurrency(t *testing.T) {
+	const limit = 2
+	limiter := newHostLimiter(limit, 1000)
+
+	var inFlight, maxInFlight atomic.Int32
+	var otherHostRan atomic.Bool
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release, err := limiter.acquire(context.Background(), "example.com")
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+			defer release()
+
+			n := inFlight.Add(1)
+			for {
+				m := maxInFlight.Load()
+				if n <= m || maxInFlight.CompareAndSwap(m, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			inFlight.Add(-1)
+		}()
+	}
+
+	// A different host must not be blocked by example.com being busy.
+	release, err := limiter.acquire(context.Background(), "other.example")
+	if err != nil {
+		t.Fatalf("unexpected error acquiring other host: %v", err)
+	}
+	otherHostRan.Store(true)
+	release()
+
+	wg.Wait()
+	if maxInFlight.Load() > limit {
+		t.Errorf("expected at most %v requests in flight to one host, got: %v", limit, maxInFlight.Load())
+	}
+	if !otherHostRan.Load() {
+		t.Errorf("expected other host to be acquired")
+	}
+}
@@ -0,0 +1,336 @@
+package scraper
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Fraegdegjevar/Gator/internal/database"
+	"github.com/Fraegdegjevar/Gator/internal/rss"
+	"github.com/google/uuid"
+)
+
+// Defaults used for any Options left as zero.
+const (
+	DefaultWorkers         = 4
+	DefaultBatchSize       = 100
+	DefaultHostConcurrency = 2
+	// Requests per second to a single host.
+	DefaultHostRate = 1.0
+	// Consecutive failures before a feed is disabled.
+	DefaultMaxFailures = 10
+	DefaultBackoffBase = time.Minute
+	DefaultBackoffMax  = 24 * time.Hour
+)
+
+// Store is the subset of database.Queries the scraper needs. Tests
+// substitute a fake.
+type Store interface {
+	GetNextFeedsToFetch(ctx context.Context, limit int32) ([]database.Feed, error)
+	CountFeeds(ctx context.Context) (int64, error)
+	MarkFeedFetched(ctx context.Context, id uuid.UUID) error
+	RecordFeedCacheHit(ctx context.Context, id uuid.UUID) error
+	UpdateFeedCache(ctx context.Context, arg database.UpdateFeedCacheParams) error
+	RecordFeedFailure(ctx context.Context, arg database.RecordFeedFailureParams) error
+	CreatePost(ctx context.Context, arg database.CreatePostParams) error
+}
+
+// Fetcher downloads feeds - implemented by *rss.Client.
+type Fetcher interface {
+	FetchFeed(ctx context.Context, feedURL string, cached rss.CacheValidators) (*rss.FetchResult, error)
+}
+
+type Options struct {
+	// Number of feeds fetched concurrently.
+	Workers int
+	// How many of the least recently fetched feeds each tick hands to
+	// the workers.
+	BatchSize int
+	// Maximum requests in flight to a single host.
+	HostConcurrency int
+	// Maximum requests per second started to a single host.
+	HostRate float64
+	// A feed that fails this many fetches in a row is disabled until
+	// re-enabled with feed-enable.
+	MaxFailures int
+	// After a failure a feed isn't retried for BackoffBase, doubling with
+	// each further consecutive failure up to BackoffMax.
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+	// Where progress is printed. Defaults to stdout.
+	Out io.Writer
+}
+
+type Scraper struct {
+	store   Store
+	fetcher Fetcher
+	opts    Options
+	limiter *hostLimiter
+}
+
+func New(store Store, fetcher Fetcher, opts Options) *Scraper {
+	if opts.Workers <= 0 {
+		opts.Workers = DefaultWorkers
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultBatchSize
+	}
+	if opts.HostConcurrency <= 0 {
+		opts.HostConcurrency = DefaultHostConcurrency
+	}
+	if opts.HostRate <= 0 {
+		opts.HostRate = DefaultHostRate
+	}
+	if opts.MaxFailures <= 0 {
+		opts.MaxFailures = DefaultMaxFailures
+	}
+	if opts.BackoffBase <= 0 {
+		opts.BackoffBase = DefaultBackoffBase
+	}
+	if opts.BackoffMax <= 0 {
+		opts.BackoffMax = DefaultBackoffMax
+	}
+	if opts.Out == nil {
+		opts.Out = os.Stdout
+	}
+	return &Scraper{
+		store:   store,
+		fetcher: fetcher,
+		opts:    opts,
+		limiter: newHostLimiter(opts.HostConcurrency, opts.HostRate),
+	}
+}
+
+// Run scrapes a batch of feeds straight away and then every interval
+// until ctx is cancelled. On cancellation no new fetches are started,
+// but fetches already in progress are allowed to finish (bounded by the
+// fetcher's own timeout) before Run returns.
+func (s *Scraper) Run(ctx context.Context, interval time.Duration) error {
+	// One slot per worker, held for the length of a fetch.
+	workers := make(chan struct{}, s.opts.Workers)
+	var fetches sync.WaitGroup
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		err := s.dispatch(ctx, workers, &fetches)
+		if err != nil {
+			fmt.Fprintf(s.opts.Out, "%v\n", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			fmt.Fprintln(s.opts.Out, "Stopping - waiting for in-flight fetches to finish...")
+			fetches.Wait()
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// dispatch starts fetching the next batch of feeds. Each host's feeds
+// wait in their own queue, so feeds held back by one busy host's limits
+// don't keep workers from feeds on other hosts. It returns once every
+// fetch in the batch has started, so a slow batch delays the next tick
+// rather than piling up work.
+func (s *Scraper) dispatch(ctx context.Context, workers chan struct{}, fetches *sync.WaitGroup) error {
+	feeds, err := s.store.GetNextFeedsToFetch(ctx, int32(s.opts.BatchSize))
+	if err != nil {
+		return fmt.Errorf("error getting next feeds to fetch: %w", err)
+	}
+	if len(feeds) == 0 {
+		count, err := s.store.CountFeeds(ctx)
+		if err != nil {
+			return fmt.Errorf("error counting feeds: %w", err)
+		}
+		if count == 0 {
+			return fmt.Errorf("no feeds to fetch - add one with addfeed")
+		}
+		// Only feeds backing off after failures or disabled are left.
+		return fmt.Errorf("no feeds due - all %v are backing off or disabled, see feeds --broken", count)
+	}
+
+	hosts := []string{}
+	queues := make(map[string][]database.Feed)
+	for _, feed := range feeds {
+		// Mark as fetched before fetching so a feed that keeps failing
+		// doesn't starve the others.
+		err := s.store.MarkFeedFetched(ctx, feed.ID)
+		if err != nil {
+			return fmt.Errorf("error marking feed %v fetched: %w", feed.Name, err)
+		}
+		host := feedHost(feed.Url)
+		if _, ok := queues[host]; !ok {
+			hosts = append(hosts, host)
+		}
+		queues[host] = append(queues[host], feed)
+	}
+
+	var started sync.WaitGroup
+	for _, host := range hosts {
+		started.Add(1)
+		go func() {
+			defer started.Done()
+			s.drainHost(ctx, host, queues[host], workers, fetches)
+		}()
+	}
+	started.Wait()
+	return nil
+}
+
+// drainHost starts fetching one host's feeds in turn, as fast as the
+// host's limits allow. Each fetch takes a worker only once the host is
+// ready for it.
+func (s *Scraper) drainHost(ctx context.Context, host string, feeds []database.Feed, workers chan struct{}, fetches *sync.WaitGroup) {
+	for _, feed := range feeds {
+		// Errors here mean we're shutting down - don't start anything new.
+		release, err := s.limiter.acquire(ctx, host)
+		if err != nil {
+			return
+		}
+		select {
+		case workers <- struct{}{}:
+		case <-ctx.Done():
+			release()
+			return
+		}
+
+		fetches.Add(1)
+		go func() {
+			defer fetches.Done()
+			// Detach from ctx so a fetch already under way drains cleanly
+			// on shutdown instead of being aborted half way through saving.
+			err := s.scrapeFeed(context.WithoutCancel(ctx), feed)
+			release()
+			<-workers
+			if err != nil {
+				fmt.Fprintf(s.opts.Out, "%v\n", err)
+			}
+		}()
+	}
+}
+
+// feedHost is the key used for per-host limits. Unparseable urls share
+// one bucket - the fetch will fail anyway.
+func feedHost(feedURL string) string {
+	u, err := url.Parse(feedURL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
+
+// scrapeFeed fetches one feed and upserts its items into posts.
+func (s *Scraper) scrapeFeed(ctx context.Context, feed database.Feed) error {
+	result, err := s.fetcher.FetchFeed(ctx, feed.Url, rss.CacheValidators{
+		ETag:         feed.Etag.String,
+		LastModified: feed.LastModified.String,
+	})
+	if err != nil {
+		return s.recordFailure(ctx, feed, err)
+	}
+
+	// Nothing has changed since last time - last_fetched_at is already
+	// updated, so just count the saving.
+	if result.NotModified {
+		err = s.store.RecordFeedCacheHit(ctx, feed.ID)
+		if err != nil {
+			return fmt.Errorf("error recording cache hit for feed %v: %w", feed.Name, err)
+		}
+		fmt.Fprintf(s.opts.Out, "Feed %v not modified since last fetch\n", feed.Name)
+		return nil
+	}
+
+	saved, failed := 0, 0
+	for _, item := range result.Feed.Items {
+		// The post url is our key, so there's nothing to store without one.
+		if item.Link == "" {
+			continue
+		}
+
+		published := item.PublishedOrUpdated()
+		publishedAt := sql.NullTime{Time: published, Valid: !published.IsZero()}
+
+		err := s.store.CreatePost(ctx, database.CreatePostParams{
+			ID:          uuid.New(),
+			CreatedAt:   time.Now(),
+			UpdatedAt:   time.Now(),
+			Title:       item.Title,
+			Url:         item.Link,
+			Description: sql.NullString{String: item.Description, Valid: item.Description != ""},
+			PublishedAt: publishedAt,
+			FeedID:      feed.ID,
+		})
+		if err != nil {
+			fmt.Fprintf(s.opts.Out, "error saving post %q: %v\n", item.Link, err)
+			failed++
+			continue
+		}
+		saved++
+	}
+
+	// If any post failed to save, forget the validators so the next fetch
+	// downloads the feed in full rather than getting a 304 and never
+	// retrying the missing posts.
+	validators := result.Validators
+	if failed > 0 {
+		validators = rss.CacheValidators{}
+	}
+	err = s.store.UpdateFeedCache(ctx, database.UpdateFeedCacheParams{
+		ID:           feed.ID,
+		Etag:         sql.NullString{String: validators.ETag, Valid: validators.ETag != ""},
+		LastModified: sql.NullString{String: validators.LastModified, Valid: validators.LastModified != ""},
+	})
+	if err != nil {
+		return fmt.Errorf("error updating cache headers for feed %v: %w", feed.Name, err)
+	}
+
+	fmt.Fprintf(s.opts.Out, "Feed %v collected, %v posts saved\n", feed.Name, saved)
+	return nil
+}
+
+// recordFailure counts a failed fetch (HTTP error, timeout, unparseable
+// feed...) against the feed and schedules the next attempt, disabling
+// the feed once it has failed MaxFailures times in a row. The returned
+// error describes what happened for the log.
+func (s *Scraper) recordFailure(ctx context.Context, feed database.Feed, fetchErr error) error {
+	failures := int(feed.FailureCount) + 1
+	disable := failures >= s.opts.MaxFailures
+	delay := backoff(failures, s.opts.BackoffBase, s.opts.BackoffMax)
+
+	err := s.store.RecordFeedFailure(ctx, database.RecordFeedFailureParams{
+		FailureCount:   int32(failures),
+		LastError:      sql.NullString{String: fetchErr.Error(), Valid: true},
+		BackoffSeconds: delay.Seconds(),
+		Disable:        disable,
+		ID:             feed.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("error recording failure for feed %v: %w (fetch error: %w)", feed.Name, err, fetchErr)
+	}
+
+	if disable {
+		return fmt.Errorf("error fetching feed %v: %w - disabled after %v failures, re-enable with feed-enable", feed.Name, fetchErr, failures)
+	}
+	return fmt.Errorf("error fetching feed %v: %w - retrying in %v", feed.Name, fetchErr, delay)
+}
+
+// backoff is how long to wait before retrying a feed that has failed
+// failures times in a row: base, 2*base, 4*base... capped at maxDelay.
+func backoff(failures int, base, maxDelay time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < failures; i++ {
+		delay *= 2
+		if delay >= maxDelay {
+			return maxDelay
+		}
+	}
+	return min(delay, maxDelay)
+}
@@ -0,0 +1,493 @@
+package scraper
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Fraegdegjevar/Gator/internal/database"
+	"github.com/Fraegdegjevar/Gator/internal/rss"
+	"github.com/google/uuid"
+)
+
+// fakeStore hands out each feed until it has been marked fetched, and
+// records what the scraper wrote.
+type fakeStore struct {
+	mu        sync.Mutex
+	feeds     []database.Feed
+	fetchedAt map[uuid.UUID]time.Time
+	posts     map[string]database.CreatePostParams
+	cacheHits map[uuid.UUID]int
+	validator map[uuid.UUID]database.UpdateFeedCacheParams
+	failures  map[uuid.UUID]database.RecordFeedFailureParams
+}
+
+func newFakeStore(feeds []database.Feed) *fakeStore {
+	return &fakeStore{
+		feeds:     feeds,
+		fetchedAt: make(map[uuid.UUID]time.Time),
+		posts:     make(map[string]database.CreatePostParams),
+		cacheHits: make(map[uuid.UUID]int),
+		validator: make(map[uuid.UUID]database.UpdateFeedCacheParams),
+		failures:  make(map[uuid.UUID]database.RecordFeedFailureParams),
+	}
+}
+
+func (f *fakeStore) GetNextFeedsToFetch(ctx context.Context, limit int32) ([]database.Feed, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	next := []database.Feed{}
+	for _, feed := range f.feeds {
+		if _, ok := f.fetchedAt[feed.ID]; !ok && len(next) < int(limit) {
+			next = append(next, feed)
+		}
+	}
+	return next, nil
+}
+
+func (f *fakeStore) CountFeeds(ctx context.Context) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return int64(len(f.feeds)), nil
+}
+
+func (f *fakeStore) MarkFeedFetched(ctx context.Context, id uuid.UUID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.fetchedAt[id] = time.Now()
+	return nil
+}
+
+func (f *fakeStore) RecordFeedCacheHit(ctx context.Context, id uuid.UUID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.cacheHits[id]++
+	return nil
+}
+
+func (f *fakeStore) UpdateFeedCache(ctx context.Context, arg database.UpdateFeedCacheParams) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.validator[arg.ID] = arg
+	return nil
+}
+
+func (f *fakeStore) RecordFeedFailure(ctx context.Context, arg database.RecordFeedFailureParams) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failures[arg.ID] = arg
+	return nil
+}
+
+func (f *fakeStore) CreatePost(ctx context.Context, arg database.CreatePostParams) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.posts[arg.Url] = arg
+	return nil
+}
+
+// fakeFetcher returns one post per feed, tracks the highest number of
+// concurrent fetches per host, and can be held up with a gate to test
+// shutdown.
+type fakeFetcher struct {
+	delay time.Duration
+	// If set, fetches block until it is closed.
+	gate chan struct{}
+	// If set, receives each feed url as its fetch starts.
+	started chan string
+	// Fetches of these urls fail with the given error.
+	errs map[string]error
+
+	mu          sync.Mutex
+	inFlight    map[string]int
+	maxInFlight map[string]int
+	completed   int
+}
+
+func newFakeFetcher(delay time.Duration) *fakeFetcher {
+	return &fakeFetcher{
+		delay:       delay,
+		inFlight:    make(map[string]int),
+		maxInFlight: make(map[string]int),
+	}
+}
+
+func (f *fakeFetcher) FetchFeed(ctx context.Context, feedURL string, cached rss.CacheValidators) (*rss.FetchResult, error) {
+	host := feedHost(feedURL)
+	f.mu.Lock()
+	f.inFlight[host]++
+	f.maxInFlight[host] = max(f.maxInFlight[host], f.inFlight[host])
+	f.mu.Unlock()
+
+	if f.started != nil {
+		f.started <- feedURL
+	}
+	if f.gate != nil {
+		<-f.gate
+	}
+	time.Sleep(f.delay)
+
+	f.mu.Lock()
+	f.inFlight[host]--
+	f.completed++
+	f.mu.Unlock()
+
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if err, ok := f.errs[feedURL]; ok {
+		return nil, err
+	}
+	return &rss.FetchResult{
+		Feed: &rss.Feed{
+			Items: []rss.Item{{Title: "post", Link: feedURL + "/post"}},
+		},
+		Validators: rss.CacheValidators{ETag: `"etag"`},
+	}, nil
+}
+
+func testFeeds(hosts []string, perHost int) []database.Feed {
+	feeds := []database.Feed{}
+	for _, host := range hosts {
+		for i := range perHost {
+			feeds = append(feeds, database.Feed{
+				ID:   uuid.New(),
+				Name: fmt.Sprintf("%v-%v", host, i),
+				Url:  fmt.Sprintf("https://%v/feed/%v", host, i),
+			})
+		}
+	}
+	return feeds
+}
+
+// waitFor polls cond until it is true or the test times out.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for condition")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestRunFetchesAllFeedsWithinHostLimits(t *testing.T) {
+	hosts := []string{"a.example", "b.example", "c.example"}
+	feeds := testFeeds(hosts, 4)
+	store := newFakeStore(feeds)
+	fetcher := newFakeFetcher(10 * time.Millisecond)
+
+	s := New(store, fetcher, Options{
+		Workers:         6,
+		HostConcurrency: 2,
+		HostRate:        1000,
+		Out:             io.Discard,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- s.Run(ctx, 5*time.Millisecond)
+	}()
+
+	waitFor(t, func() bool {
+		store.mu.Lock()
+		defer store.mu.Unlock()
+		return len(store.validator) == len(feeds)
+	})
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error from Run: %v", err)
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if len(store.posts) != len(feeds) {
+		t.Errorf("expected %v posts saved, got: %v", len(feeds), len(store.posts))
+	}
+	for _, feed := range feeds {
+		if store.validator[feed.ID].Etag.String != `"etag"` {
+			t.Errorf("expected etag stored for feed %v, got: %+v", feed.Name, store.validator[feed.ID])
+		}
+	}
+
+	fetcher.mu.Lock()
+	defer fetcher.mu.Unlock()
+	for host, n := range fetcher.maxInFlight {
+		if n > 2 {
+			t.Errorf("expected at most 2 concurrent fetches to %v, got: %v", host, n)
+		}
+	}
+}
+
+func TestRunFetchesMoreFeedsThanWorkersPerTick(t *testing.T) {
+	feeds := testFeeds([]string{"a.example", "b.example"}, 5)
+	store := newFakeStore(feeds)
+
+	s := New(store, newFakeFetcher(0), Options{
+		Workers:  1,
+		HostRate: 1000,
+		Out:      io.Discard,
+	})
+
+	// One tick only: every feed has to come from the first batch.
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- s.Run(ctx, time.Hour)
+	}()
+	waitFor(t, func() bool {
+		store.mu.Lock()
+		defer store.mu.Unlock()
+		return len(store.validator) == len(feeds)
+	})
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error from Run: %v", err)
+	}
+}
+
+func TestRunBusyHostDoesNotHoldUpOthers(t *testing.T) {
+	// The busy host's feeds come first and its rate limit allows one
+	// fetch a second, so they would take every worker if workers waited
+	// on it.
+	feeds := append(testFeeds([]string{"busy.example"}, 6), testFeeds([]string{"a.example", "b.example"}, 1)...)
+	store := newFakeStore(feeds)
+
+	s := New(store, newFakeFetcher(0), Options{
+		Workers:         2,
+		HostConcurrency: 1,
+		HostRate:        1,
+		Out:             io.Discard,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- s.Run(ctx, time.Hour)
+	}()
+
+	busyDone := 0
+	waitFor(t, func() bool {
+		store.mu.Lock()
+		defer store.mu.Unlock()
+		busyDone = 0
+		for _, feed := range feeds[:6] {
+			if _, ok := store.validator[feed.ID]; ok {
+				busyDone++
+			}
+		}
+		_, a := store.validator[feeds[6].ID]
+		_, b := store.validator[feeds[7].ID]
+		return a && b
+	})
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error from Run: %v", err)
+	}
+	if busyDone > 2 {
+		t.Errorf("expected the other hosts' feeds before most of the busy host's, got %v busy feeds first", busyDone)
+	}
+}
+
+func TestRunDrainsInFlightFetchesOnShutdown(t *testing.T) {
+	feeds := testFeeds([]string{"a.example", "b.example"}, 1)
+	store := newFakeStore(feeds)
+	fetcher := newFakeFetcher(0)
+	fetcher.gate = make(chan struct{})
+	fetcher.started = make(chan string, len(feeds))
+
+	s := New(store, fetcher, Options{
+		Workers:  2,
+		HostRate: 1000,
+		Out:      io.Discard,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- s.Run(ctx, time.Hour)
+	}()
+
+	// Both fetches are under way - now ask to shut down.
+	<-fetcher.started
+	<-fetcher.started
+	cancel()
+
+	select {
+	case <-done:
+		t.Fatalf("Run returned before in-flight fetches finished")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(fetcher.gate)
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error from Run: %v", err)
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if len(store.posts) != len(feeds) {
+		t.Errorf("expected in-flight fetches to finish saving %v posts, got: %v", len(feeds), len(store.posts))
+	}
+}
+
+func TestRunStopsWithNoFeeds(t *testing.T) {
+	store := newFakeStore(nil)
+	s := New(store, newFakeFetcher(0), Options{Out: io.Discard})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := s.Run(ctx, time.Millisecond)
+	if err != nil {
+		t.Errorf("unexpected error from Run: %v", err)
+	}
+	if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		t.Errorf("expected Run to return only once ctx was done")
+	}
+}
+
+func TestDispatchNoFeeds(t *testing.T) {
+	cases := []struct {
+		name          string
+		feeds         []database.Feed
+		fetched       bool
+		expectedError string
+	}{
+		{
+			name:          "no feeds",
+			expectedError: "no feeds to fetch - add one with addfeed",
+		},
+		{
+			name:          "no feeds due",
+			feeds:         testFeeds([]string{"a.example"}, 2),
+			fetched:       true,
+			expectedError: "no feeds due - all 2 are backing off or disabled, see feeds --broken",
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newFakeStore(tt.feeds)
+			if tt.fetched {
+				for _, feed := range tt.feeds {
+					store.fetchedAt[feed.ID] = time.Now()
+				}
+			}
+			s := New(store, newFakeFetcher(0), Options{Out: io.Discard})
+
+			var fetches sync.WaitGroup
+			err := s.dispatch(context.Background(), make(chan struct{}, 1), &fetches)
+			if err == nil || err.Error() != tt.expectedError {
+				t.Errorf("expected error: %v, got: %v", tt.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestNewDefaults(t *testing.T) {
+	s := New(newFakeStore(nil), newFakeFetcher(0), Options{})
+	if s.opts.Workers != DefaultWorkers {
+		t.Errorf("expected default workers: %v, got: %v", DefaultWorkers, s.opts.Workers)
+	}
+	if s.opts.BatchSize != DefaultBatchSize {
+		t.Errorf("expected default batch size: %v, got: %v", DefaultBatchSize, s.opts.BatchSize)
+	}
+	if s.opts.HostConcurrency != DefaultHostConcurrency {
+		t.Errorf("expected default host concurrency: %v, got: %v", DefaultHostConcurrency, s.opts.HostConcurrency)
+	}
+	if s.opts.HostRate != DefaultHostRate {
+		t.Errorf("expected default host rate: %v, got: %v", DefaultHostRate, s.opts.HostRate)
+	}
+}
+
+func TestRunRecordsFailures(t *testing.T) {
+	errFetch := errors.New("unexpected status 500 Internal Server Error")
+	feeds := testFeeds([]string{"a.example"}, 3)
+	// feeds[0] has never failed, feeds[1] is one failure from being
+	// disabled, feeds[2] works.
+	feeds[1].FailureCount = 2
+	store := newFakeStore(feeds)
+	fetcher := newFakeFetcher(0)
+	fetcher.errs = map[string]error{
+		feeds[0].Url: errFetch,
+		feeds[1].Url: errFetch,
+	}
+
+	s := New(store, fetcher, Options{
+		Workers:     3,
+		HostRate:    1000,
+		MaxFailures: 3,
+		BackoffBase: time.Minute,
+		Out:         io.Discard,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- s.Run(ctx, time.Hour)
+	}()
+	waitFor(t, func() bool {
+		store.mu.Lock()
+		defer store.mu.Unlock()
+		return len(store.failures) == 2 && len(store.validator) == 1
+	})
+	cancel()
+	<-done
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	expected := map[uuid.UUID]database.RecordFeedFailureParams{
+		feeds[0].ID: {
+			FailureCount:   1,
+			LastError:      sql.NullString{String: errFetch.Error(), Valid: true},
+			BackoffSeconds: 60,
+			Disable:        false,
+			ID:             feeds[0].ID,
+		},
+		feeds[1].ID: {
+			FailureCount:   3,
+			LastError:      sql.NullString{String: errFetch.Error(), Valid: true},
+			BackoffSeconds: 240,
+			Disable:        true,
+			ID:             feeds[1].ID,
+		},
+	}
+	if !reflect.DeepEqual(expected, store.failures) {
+		t.Errorf("expected failures: %+v, got: %+v", expected, store.failures)
+	}
+	if _, ok := store.validator[feeds[2].ID]; !ok {
+		t.Errorf("expected working feed to be stored as a success")
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	cases := []struct {
+		failures int
+		expected time.Duration
+	}{
+		{failures: 1, expected: time.Minute},
+		{failures: 2, expected: 2 * time.Minute},
+		{failures: 3, expected: 4 * time.Minute},
+		{failures: 6, expected: 32 * time.Minute},
+		{failures: 7, expected: time.Hour},
+		{failures: 100, expected: time.Hour},
+	}
+
+	for _, tt := range cases {
+		t.Run(fmt.Sprintf("%v failures", tt.failures), func(t *testing.T) {
+			got := backoff(tt.failures, time.Minute, time.Hour)
+			if got != tt.expected {
+				t.Errorf("expected backoff: %v, got: %v", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package shell
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+)
+
+// Complete completes the word before pos in line. candidates is given
+// the words before that one and returns every value it could take; those
+// starting with what has been typed so far are the matches. A single
+// match replaces the word (quoted if need be) followed by a space.
+// Several extend it to their longest common prefix and are returned so
+// the caller can list them. ok is false if there was nothing to do.
+func Complete(line string, pos int, candidates func(words []string) []string) (newLine string, newPos int, matches []string, ok bool) {
+	before := line[:pos]
+	words, err := Split(before)
+	if err != nil && !errors.Is(err, ErrUnterminatedQuote) {
+		return line, pos, nil, false
+	}
+
+	// The word being completed starts at partialStart. After trailing
+	// whitespace (outside a quote) it's a new, empty word.
+	partial, partialStart := "", pos
+	endsInSpace := strings.HasSuffix(before, " ") || strings.HasSuffix(before, "\t")
+	if len(words) > 0 && (!endsInSpace || errors.Is(err, ErrUnterminatedQuote)) {
+		last := words[len(words)-1]
+		partial, partialStart = last.Text, last.Start
+		words = words[:len(words)-1]
+	}
+
+	previous := []string{}
+	for _, word := range words {
+		previous = append(previous, word.Text)
+	}
+	for _, candidate := range candidates(previous) {
+		if strings.HasPrefix(candidate, partial) {
+			matches = append(matches, candidate)
+		}
+	}
+
+	var replacement string
+	switch len(matches) {
+	case 0:
+		return line, pos, nil, false
+	case 1:
+		replacement = Quote(matches[0]) + " "
+		matches = nil
+	default:
+		prefix := commonPrefix(matches)
+		if prefix == partial {
+			return line, pos, matches, true
+		}
+		replacement = prefix
+		if Quote(prefix) != prefix {
+			// Leave the quote open so typing can carry on inside it.
+			replacement = strings.TrimSuffix(Quote(prefix), Quote(prefix)[:1])
+		}
+	}
+
+	newLine = line[:partialStart] + replacement + line[pos:]
+	return newLine, partialStart + len(replacement), matches, true
+}
+
+// commonPrefix is the longest prefix every string in words shares.
+func commonPrefix(words []string) string {
+	prefix := words[0]
+	for _, word := range words[1:] {
+		for !strings.HasPrefix(word, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	// Don't split a multi-byte character the words only partly share.
+	for !utf8.ValidString(prefix) {
+		prefix = prefix[:len(prefix)-1]
+	}
+	return prefix
+}
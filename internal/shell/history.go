@@ -0,0 +1,75 @@
+package shell
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Fraegdegjevar/Gator/internal/config"
+)
+
+// History is the shell's command history, kept to the most recent max
+// lines. It satisfies term.History so the up and down arrows can walk it.
+type History struct {
+	// Oldest first.
+	entries []string
+	max     int
+}
+
+func NewHistory(max int) *History {
+	return &History{max: max}
+}
+
+// Add records a line, skipping blank lines and repeats of the last one.
+func (h *History) Add(entry string) {
+	if strings.TrimSpace(entry) == "" {
+		return
+	}
+	if len(h.entries) > 0 && h.entries[len(h.entries)-1] == entry {
+		return
+	}
+	h.entries = append(h.entries, entry)
+	if len(h.entries) > h.max {
+		h.entries = h.entries[len(h.entries)-h.max:]
+	}
+}
+
+func (h *History) Len() int {
+	return len(h.entries)
+}
+
+// At returns an entry counting back from the most recent, which is 0.
+func (h *History) At(idx int) string {
+	return h.entries[len(h.entries)-1-idx]
+}
+
+// LoadHistory reads a history file written by Save. A missing file is
+// an empty history.
+func LoadHistory(fs config.FileSystem, path string, max int) (*History, error) {
+	h := NewHistory(max)
+	data, err := fs.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return h, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading history from %v: %w", path, err)
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		h.Add(line)
+	}
+	return h, nil
+}
+
+// Save writes the history to path, one line per entry, oldest first.
+func (h *History) Save(fs config.FileSystem, path string) error {
+	data := strings.Join(h.entries, "\n")
+	if len(h.entries) > 0 {
+		data += "\n"
+	}
+	err := fs.WriteFile(path, []byte(data), 0600)
+	if err != nil {
+		return fmt.Errorf("error saving history to %v: %w", path, err)
+	}
+	return nil
+}
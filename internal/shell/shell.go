@@ -0,0 +1,175 @@
+package shell
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/Fraegdegjevar/Gator/internal/config"
+	"golang.org/x/term"
+)
+
+// A Shell reads command lines one at a time and runs them until the user
+// types exit or quit, or ends input with Ctrl-D.
+type Shell struct {
+	Prompt string
+	// Run runs one command line, already split into words.
+	Run func(ctx context.Context, args []string) error
+	// Candidates returns every value the word after words could take,
+	// for tab completion. It may be nil.
+	Candidates func(ctx context.Context, words []string) []string
+
+	History *History
+	// Where History is saved after each line. Empty means don't save.
+	HistoryFile string
+	FS          config.FileSystem
+}
+
+// Interactive reports whether in is a terminal, as opposed to input
+// piped in from a script.
+func Interactive(in *os.File) bool {
+	return term.IsTerminal(int(in.Fd()))
+}
+
+// Loop runs the shell until input ends or ctx is cancelled, writing
+// command errors to errOut. When in is a terminal, lines are read with
+// editing, history on the up and down arrows and tab completion;
+// otherwise (input piped in from a script) lines are read as they are,
+// with no prompt.
+func (sh *Shell) Loop(ctx context.Context, in *os.File, out *os.File, errOut *os.File) error {
+	if !Interactive(in) {
+		return sh.loopPlain(ctx, in, errOut)
+	}
+	return sh.loopTerminal(ctx, in, out, errOut)
+}
+
+func (sh *Shell) loopPlain(ctx context.Context, in io.Reader, errOut io.Writer) error {
+	scanner := bufio.NewScanner(in)
+	for ctx.Err() == nil && scanner.Scan() {
+		if !sh.runLine(ctx, errOut, scanner.Text()) {
+			return nil
+		}
+	}
+	return scanner.Err()
+}
+
+func (sh *Shell) loopTerminal(ctx context.Context, in *os.File, out *os.File, errOut *os.File) error {
+	fd := int(in.Fd())
+	keys := &interruptReader{r: in}
+	t := sh.newTerminal(ctx, keys, out)
+
+	for ctx.Err() == nil {
+		// The terminal is only raw while reading, so commands print
+		// normally and Ctrl-C interrupts them as usual.
+		if width, height, err := term.GetSize(fd); err == nil {
+			t.SetSize(width, height)
+		}
+		oldState, err := term.MakeRaw(fd)
+		if err != nil {
+			return fmt.Errorf("error setting up terminal: %w", err)
+		}
+		line, err := t.ReadLine()
+		term.Restore(fd, oldState)
+
+		// Ctrl-C throws away the line being typed, as in bash. The
+		// terminal would keep it, so start again with a new one.
+		if errors.Is(err, io.EOF) && keys.interrupted {
+			fmt.Fprintln(out, "^C")
+			t = sh.newTerminal(ctx, keys, out)
+			continue
+		}
+		if errors.Is(err, io.EOF) {
+			fmt.Fprintln(out)
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error reading input: %w", err)
+		}
+		if !sh.runLine(ctx, errOut, line) {
+			return nil
+		}
+	}
+	return nil
+}
+
+func (sh *Shell) newTerminal(ctx context.Context, in io.Reader, out io.Writer) *term.Terminal {
+	t := term.NewTerminal(struct {
+		io.Reader
+		io.Writer
+	}{in, out}, sh.Prompt)
+	if sh.History != nil {
+		t.History = sh.History
+	}
+	if sh.Candidates != nil {
+		t.AutoCompleteCallback = func(line string, pos int, key rune) (string, int, bool) {
+			if key != '\t' {
+				return "", 0, false
+			}
+			newLine, newPos, matches, ok := Complete(line, pos, func(words []string) []string {
+				return sh.Candidates(ctx, words)
+			})
+			if len(matches) > 0 {
+				fmt.Fprintln(t, strings.Join(matches, "  "))
+			}
+			return newLine, newPos, ok
+		}
+	}
+	return t
+}
+
+// interruptReader notes whether the last input read held a Ctrl-C.
+// term.Terminal returns io.EOF for Ctrl-C as well as Ctrl-D, but only
+// Ctrl-D should end the shell.
+type interruptReader struct {
+	r           io.Reader
+	interrupted bool
+}
+
+func (ir *interruptReader) Read(p []byte) (int, error) {
+	n, err := ir.r.Read(p)
+	ir.interrupted = bytes.IndexByte(p[:n], ctrlC) >= 0
+	return n, err
+}
+
+const ctrlC = 3
+
+// runLine runs one line of input, reporting any error to errOut, and
+// returns false if the user asked to leave.
+func (sh *Shell) runLine(ctx context.Context, errOut io.Writer, line string) bool {
+	words, err := Split(line)
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+		return true
+	}
+	if len(words) == 0 {
+		return true
+	}
+	args := []string{}
+	for _, word := range words {
+		args = append(args, word.Text)
+	}
+	if args[0] == "exit" || args[0] == "quit" {
+		return false
+	}
+
+	// Ctrl-C cancels the command that's running, not the shell.
+	cmdCtx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	err = sh.Run(cmdCtx, args)
+	stop()
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+	}
+
+	if sh.History != nil && sh.HistoryFile != "" {
+		if err := sh.History.Save(sh.FS, sh.HistoryFile); err != nil {
+			fmt.Fprintln(errOut, err)
+		}
+	}
+	return true
+}
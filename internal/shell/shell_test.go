@@ -0,0 +1,231 @@
+package shell
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Fraegdegjevar/Gator/internal/config"
+)
+
+func TestSplit(t *testing.T) {
+	cases := []struct {
+		name        string
+		line        string
+		expected    []Word
+		expectedErr error
+	}{
+		{
+			name:     "blank line",
+			line:     "   ",
+			expected: []Word{},
+		},
+		{
+			name:     "plain words",
+			line:     "browse  --unread 5",
+			expected: []Word{{"browse", 0}, {"--unread", 8}, {"5", 17}},
+		},
+		{
+			name:     "quotes group words",
+			line:     `addfeed "Go Blog" 'it''s'`,
+			expected: []Word{{"addfeed", 0}, {"Go Blog", 8}, {"its", 18}},
+		},
+		{
+			name:     "backslash escapes",
+			line:     `search a\ b "say \"hi\"" 'no\escape'`,
+			expected: []Word{{"search", 0}, {"a b", 7}, {`say "hi"`, 12}, {`no\escape`, 25}},
+		},
+		{
+			name:        "unterminated quote",
+			line:        `follow 'https://go`,
+			expected:    []Word{{"follow", 0}, {"https://go", 7}},
+			expectedErr: ErrUnterminatedQuote,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			words, err := Split(tt.line)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("expected err: %v, got: %v", tt.expectedErr, err)
+			}
+			if !reflect.DeepEqual(words, tt.expected) {
+				t.Errorf("expected words: %v, got: %v", tt.expected, words)
+			}
+		})
+	}
+}
+
+func TestQuote(t *testing.T) {
+	words := []string{"plain", "", "two words", "it's", `back\slash`, `"quoted" and 'single'`}
+	for _, word := range words {
+		split, err := Split("cmd " + Quote(word))
+		if err != nil {
+			t.Fatalf("expected no error splitting %q, got: %v", Quote(word), err)
+		}
+		if len(split) != 2 || split[1].Text != word {
+			t.Errorf("expected %q to round trip, got: %v", word, split)
+		}
+	}
+}
+
+func TestComplete(t *testing.T) {
+	candidates := func(words []string) []string {
+		if len(words) == 0 {
+			return []string{"browse", "feeds", "follow", "following"}
+		}
+		if words[0] == "mark-all-read" {
+			return []string{"Go Blog", "Go Weekly"}
+		}
+		return nil
+	}
+
+	cases := []struct {
+		name            string
+		line            string
+		expectedLine    string
+		expectedMatches []string
+		expectedOk      bool
+	}{
+		{
+			name:         "single match",
+			line:         "br",
+			expectedLine: "browse ",
+			expectedOk:   true,
+		},
+		{
+			name:            "common prefix",
+			line:            "fo",
+			expectedLine:    "follow",
+			expectedMatches: []string{"follow", "following"},
+			expectedOk:      true,
+		},
+		{
+			name:            "no progress lists matches",
+			line:            "follow",
+			expectedLine:    "follow",
+			expectedMatches: []string{"follow", "following"},
+			expectedOk:      true,
+		},
+		{
+			name:         "no match",
+			line:         "xyz",
+			expectedLine: "xyz",
+		},
+		{
+			name:            "argument prefix left open in quote",
+			line:            "mark-all-read G",
+			expectedLine:    "mark-all-read 'Go ",
+			expectedMatches: []string{"Go Blog", "Go Weekly"},
+			expectedOk:      true,
+		},
+		{
+			name:         "argument inside quote",
+			line:         "mark-all-read 'Go B",
+			expectedLine: "mark-all-read 'Go Blog' ",
+			expectedOk:   true,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			line, pos, matches, ok := Complete(tt.line, len(tt.line), candidates)
+			if ok != tt.expectedOk {
+				t.Errorf("expected ok: %v, got: %v", tt.expectedOk, ok)
+			}
+			if line != tt.expectedLine || pos != len(tt.expectedLine) {
+				t.Errorf("expected line: %q, got: %q (pos %v)", tt.expectedLine, line, pos)
+			}
+			if !reflect.DeepEqual(matches, tt.expectedMatches) {
+				t.Errorf("expected matches: %v, got: %v", tt.expectedMatches, matches)
+			}
+		})
+	}
+}
+
+func TestInterruptReader(t *testing.T) {
+	// Each key arrives in its own read, as when typed.
+	keys := &interruptReader{r: &oneByteReader{strings.NewReader("fe\x03feeds\r\x04")}}
+	sh := &Shell{Prompt: "> "}
+
+	term := sh.newTerminal(context.Background(), keys, io.Discard)
+	_, err := term.ReadLine()
+	if !errors.Is(err, io.EOF) || !keys.interrupted {
+		t.Fatalf("expected Ctrl-C to be an interrupt, got: %v (interrupted %v)", err, keys.interrupted)
+	}
+
+	term = sh.newTerminal(context.Background(), keys, io.Discard)
+	line, err := term.ReadLine()
+	if err != nil || line != "feeds" {
+		t.Fatalf("expected the line after Ctrl-C to start afresh, got: %q, %v", line, err)
+	}
+	_, err = term.ReadLine()
+	if !errors.Is(err, io.EOF) || keys.interrupted {
+		t.Errorf("expected Ctrl-D to end input, got: %v (interrupted %v)", err, keys.interrupted)
+	}
+}
+
+type oneByteReader struct {
+	r io.Reader
+}
+
+func (o *oneByteReader) Read(p []byte) (int, error) {
+	return o.r.Read(p[:1])
+}
+
+func TestHistory(t *testing.T) {
+	fs := &config.FakeFileSystem{Files: make(map[string][]byte)}
+
+	h, err := LoadHistory(fs, "home/.gator_history", 3)
+	if err != nil {
+		t.Fatalf("expected a missing file to be an empty history, got: %v", err)
+	}
+	for _, line := range []string{"feeds", "feeds", "  ", "browse", "following", "agg 1m"} {
+		h.Add(line)
+	}
+	if h.Len() != 3 || h.At(0) != "agg 1m" || h.At(2) != "browse" {
+		t.Fatalf("expected the 3 most recent distinct lines, got: %v", h.entries)
+	}
+
+	if err := h.Save(fs, "home/.gator_history"); err != nil {
+		t.Fatalf("expected no error saving, got: %v", err)
+	}
+	loaded, err := LoadHistory(fs, "home/.gator_history", 3)
+	if err != nil {
+		t.Fatalf("expected no error loading, got: %v", err)
+	}
+	if !reflect.DeepEqual(loaded.entries, h.entries) {
+		t.Errorf("expected loaded history: %v, got: %v", h.entries, loaded.entries)
+	}
+}
+
+func TestLoopPlain(t *testing.T) {
+	errMock := errors.New("mock error")
+	ran := [][]string{}
+	sh := &Shell{
+		Run: func(ctx context.Context, args []string) error {
+			ran = append(ran, args)
+			if args[0] == "fail" {
+				return errMock
+			}
+			return nil
+		},
+	}
+
+	errOut := &strings.Builder{}
+	in := strings.NewReader("feeds\nfail now\nbrowse 'Go Blog\nexit\nbrowse\n")
+	if err := sh.loopPlain(context.Background(), in, errOut); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := [][]string{{"feeds"}, {"fail", "now"}}
+	if !reflect.DeepEqual(expected, ran) {
+		t.Errorf("expected to run: %q, got: %q", expected, ran)
+	}
+	expectedErrs := errMock.Error() + "\n" + ErrUnterminatedQuote.Error() + "\n"
+	if errOut.String() != expectedErrs {
+		t.Errorf("expected errors: %q, got: %q", expectedErrs, errOut.String())
+	}
+}
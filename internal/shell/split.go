@@ -0,0 +1,78 @@
+package shell
+
+import (
+	"errors"
+	"strings"
+)
+
+var ErrUnterminatedQuote = errors.New("unterminated quote")
+
+// A Word is one word of a command line and the byte offset it starts at.
+type Word struct {
+	Text  string
+	Start int
+}
+
+// Split breaks line into words the way a POSIX shell does for simple
+// cases: whitespace separates words, single or double quotes group them
+// and a backslash escapes the next character (except inside single
+// quotes). An unterminated quote runs to the end of the line; the words
+// are still returned, along with ErrUnterminatedQuote, so completion can
+// work on a half-typed line.
+func Split(line string) ([]Word, error) {
+	words := []Word{}
+	var b strings.Builder
+	inWord := false
+	start := 0
+	var quote rune
+	escaped := false
+
+	for i, r := range line {
+		switch {
+		case !escaped && quote == 0 && (r == ' ' || r == '\t'):
+			if inWord {
+				words = append(words, Word{Text: b.String(), Start: start})
+				b.Reset()
+				inWord = false
+			}
+			continue
+		case !inWord:
+			inWord = true
+			start = i
+		}
+
+		switch {
+		case escaped:
+			b.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+		case r == quote:
+			quote = 0
+		case quote == 0 && (r == '"' || r == '\''):
+			quote = r
+		default:
+			b.WriteRune(r)
+		}
+	}
+	if inWord {
+		words = append(words, Word{Text: b.String(), Start: start})
+	}
+	if quote != 0 {
+		return words, ErrUnterminatedQuote
+	}
+	return words, nil
+}
+
+// Quote returns word as it should be typed for Split to read it back,
+// wrapping it in quotes if it contains whitespace or quotes.
+func Quote(word string) string {
+	if word != "" && !strings.ContainsAny(word, " \t\"'\\") {
+		return word
+	}
+	if !strings.Contains(word, "'") {
+		return "'" + word + "'"
+	}
+	escaped := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(word)
+	return `"` + escaped + `"`
+}
@@ -0,0 +1,33 @@
+// Package testutil holds helpers shared by the tests of other packages.
+package testutil
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Run tests with -update to rewrite the golden files from the current
+// output.
+var update = flag.Bool("update", false, "update golden files")
+
+// Golden compares got with testdata/name in the package under test, or
+// rewrites it with -update.
+func Golden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatalf("error updating golden file: %v", err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("error reading golden file: %v", err)
+	}
+	if !bytes.Equal(want, got) {
+		t.Errorf("output doesn't match %v\nexpected:\n%s\ngot:\n%s", path, want, got)
+	}
+}
@@ -3,68 +3,159 @@ package main
 // note the _ before the /lib/pq import shows that we need to import the driver for
 // side effects and not direct usage.
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"net/url"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/Fraegdegjevar/Gator/internal/command"
 	"github.com/Fraegdegjevar/Gator/internal/config"
 	"github.com/Fraegdegjevar/Gator/internal/database"
+	"github.com/Fraegdegjevar/Gator/internal/output"
 	_ "github.com/lib/pq"
 )
 
+// How long connecting to postgres may take before we give up.
+const connectTimeout = 10 * time.Second
+
+// connect opens the database at dbURL. sql.Open only checks the url
+// parses, so ping to find out whether postgres is actually there.
+func connect(ctx context.Context, dbURL string) (*database.Queries, error) {
+	db, err := sql.Open("postgres", dbURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid db_url in ~/.gatorconfig.json: %w", command.ErrDBUnavailable, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, connectTimeout)
+	defer cancel()
+	err = db.PingContext(ctx)
+	if err != nil {
+		db.Close()
+		// Don't print the password.
+		where := "the db_url in ~/.gatorconfig.json"
+		if u, parseErr := url.Parse(dbURL); parseErr == nil && u.Host != "" {
+			where = u.Redacted()
+		}
+		return nil, fmt.Errorf("%w: can't connect to postgres at %v: %w", command.ErrDBUnavailable, where, err)
+	}
+	return database.New(db), nil
+}
+
 func main() {
 	// Set our real, OSFileSystem
 	fs := config.OSFileSystem{}
 
 	conf, err := config.Read(fs)
 	if err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(command.ExitError)
 	}
 
+	// The database is only connected to when a command needs it, so
+	// help, completion and the like work while postgres is down.
 	s := &command.State{
 		Config: &conf,
+		Out:    output.New(os.Stdout, os.Stderr, output.Plain),
+		Connect: func(ctx context.Context) (*database.Queries, error) {
+			return connect(ctx, conf.DBURL)
+		},
 	}
-	// Open a connection to db
-	db, err := sql.Open("postgres", s.Config.DBURL)
-	if err != nil {
-		fmt.Printf("error connecting to database: %v\n", err)
-		os.Exit(1)
-	}
-
-	// Store database query object in state.
-	fmt.Println("connected to postgres")
-	s.Db = database.New(db)
 
-	cmds := &command.Commands{
-		Registry: make(map[string]func(config.FileSystem, *command.State, command.Command) error),
+	cmds := command.NewCommands()
+	for _, d := range command.Builtins() {
+		cmds.Register(d)
 	}
-	cmds.Register("login", command.HandlerLogin)
-	cmds.Register("register", command.HandlerRegister)
-	cmds.Register("reset", command.HandlerReset)
+	cmds.Use(command.Recover())
 
-	// Note: this will not be an interactive program, i.e
-	// no repl. So we need to read in arguments when the
-	// executable is called on the commandline with os.Args
+	// Note: most commands run once and exit, so we read in
+	// arguments when the executable is called on the commandline
+	// with os.Args. "gator shell" is the exception - it reads
+	// further commands itself and runs them against this same State.
 
-	input := os.Args
-	if len(input) < 2 {
-		fmt.Println("Please enter a command.")
-		os.Exit(1)
-	}
 	//Note: os.Args is a []string of all args supplied on
 	// the command line. That includes the program name
 	// (i.e ./Gator or even (go run .))
 	// os.Args[0] -> Program Name (guaranteed if this program is running)
-	// os.Args[1] -> SHOULD be a command name.
-	// os.Args[2:] -> OPTIONAL args for the command (not all cmds need args)
-	commandName := input[1]
+	// os.Args[1:] -> OPTIONAL global options (e.g --time), then
+	// the command name and its args (not all cmds need args)
+	input := os.Args[1:]
+	for len(input) > 0 && strings.HasPrefix(input[0], "--") {
+		option, value, hasValue := strings.Cut(input[0], "=")
+		switch option {
+		case "--time":
+			// Report how long the command took on stderr.
+			cmds.Use(command.Timing(os.Stderr))
+		case "--timeout":
+			// Bound how long the command may take (agg excepted).
+			if !hasValue {
+				if len(input) < 2 {
+					fmt.Fprintln(os.Stderr, "--timeout needs a duration, e.g. --timeout 30s")
+					os.Exit(command.ExitUsage)
+				}
+				input = input[1:]
+				value = input[0]
+			}
+			timeout, err := time.ParseDuration(value)
+			if err != nil || timeout <= 0 {
+				fmt.Fprintf(os.Stderr, "invalid --timeout %q - use a duration like 30s or 2m\n", value)
+				os.Exit(command.ExitUsage)
+			}
+			cmds.Use(command.Timeout(timeout))
+		case "--output":
+			// Render results as plain text (the default), an aligned
+			// table or JSON.
+			if !hasValue {
+				if len(input) < 2 {
+					fmt.Fprintln(os.Stderr, "--output needs a format: plain, table or json")
+					os.Exit(command.ExitUsage)
+				}
+				input = input[1:]
+				value = input[0]
+			}
+			format, err := output.ParseFormat(value)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(command.ExitUsage)
+			}
+			s.Out = output.New(os.Stdout, os.Stderr, format)
+		default:
+			fmt.Fprintf(os.Stderr, "unknown option %v - run \"gator help\" to list commands\n", input[0])
+			os.Exit(command.ExitUsage)
+		}
+		input = input[1:]
+	}
+	if len(input) < 1 {
+		fmt.Fprintln(os.Stderr, "Please enter a command - run \"gator help\" to list them.")
+		os.Exit(command.ExitUsage)
+	}
+	commandName := input[0]
 	//fmt.Printf("command: %v\n", commandName)
-	commandArgs := input[2:]
+	commandArgs := input[1:]
 	//fmt.Printf("args: %v\n", commandArgs)
 
+	// ctx is cancelled on Ctrl-C (SIGINT) or SIGTERM so commands can
+	// stop what they're doing and return normally, running any deferred
+	// cleanup, rather than the process being killed mid-query. A second
+	// Ctrl-C falls through to the default handler and kills us outright.
+	// Commands that handle Ctrl-C themselves only listen for SIGTERM here.
+	signals := []os.Signal{os.Interrupt, syscall.SIGTERM}
+	// An alias for such a command counts too.
+	if cmds.HandlesInterrupt(s, command.Command{Name: commandName, Args: commandArgs}) {
+		signals = []os.Signal{syscall.SIGTERM}
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), signals...)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
 	err = cmds.Run(
+		ctx,
 		fs,
 		s,
 		command.Command{
@@ -73,9 +164,11 @@ func main() {
 		},
 	)
 	if err != nil {
-		fmt.Printf("%v\n", err)
-		os.Exit(1)
+		// Errors go to stderr so they can't be mistaken for results.
+		fmt.Fprintln(os.Stderr, err)
 	}
-
-	os.Exit(0)
+	// Work out the exit code before stop, which cancels ctx.
+	code := command.ExitCode(ctx, err)
+	stop()
+	os.Exit(code)
 }
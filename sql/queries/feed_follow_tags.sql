@@ -0,0 +1,44 @@
+-- name: TagFeedFollow :execrows
+INSERT INTO feed_follow_tags(feed_follow_id, tag)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: UntagFeedFollow :execrows
+DELETE FROM feed_follow_tags
+WHERE feed_follow_id = $1
+AND tag = $2;
+
+-- name: ClearFeedFollowTags :execrows
+DELETE FROM feed_follow_tags
+WHERE feed_follow_id = $1;
+
+-- name: GetFeedFollowTagsForUser :many
+SELECT feed_follow_tags.* FROM feed_follow_tags
+INNER JOIN feed_follows ON feed_follows.id = feed_follow_tags.feed_follow_id
+WHERE feed_follows.user_id = $1
+ORDER BY feed_follow_tags.tag;
+
+-- name: GetTagsForUser :many
+WITH follow_unread AS (
+    SELECT
+        feed_follows.id,
+        (
+            SELECT COUNT(*) FROM posts
+            WHERE posts.feed_id = feed_follows.feed_id
+            AND NOT EXISTS (
+                SELECT 1 FROM post_reads
+                WHERE post_reads.post_id = posts.id
+                AND post_reads.user_id = feed_follows.user_id
+            )
+        ) AS unread_count
+    FROM feed_follows
+    WHERE feed_follows.user_id = $1
+)
+SELECT
+    feed_follow_tags.tag,
+    COUNT(*) AS feed_count,
+    SUM(follow_unread.unread_count)::bigint AS unread_count
+FROM feed_follow_tags
+INNER JOIN follow_unread ON follow_unread.id = feed_follow_tags.feed_follow_id
+GROUP BY feed_follow_tags.tag
+ORDER BY feed_follow_tags.tag;
@@ -0,0 +1,55 @@
+-- name: CreateFeedFollow :one
+WITH inserted_feed_follow AS (
+    INSERT INTO feed_follows(id, created_at, updated_at, user_id, feed_id)
+    VALUES (
+        $1,
+        $2,
+        $3,
+        $4,
+        $5
+    )
+    RETURNING *
+)
+SELECT
+    inserted_feed_follow.*,
+    feeds.name AS feed_name,
+    users.name AS user_name
+FROM inserted_feed_follow
+INNER JOIN feeds ON feeds.id = inserted_feed_follow.feed_id
+INNER JOIN users ON users.id = inserted_feed_follow.user_id;
+
+-- name: GetFeedFollowsForUser :many
+SELECT
+    feed_follows.*,
+    feeds.name AS feed_name,
+    feeds.url AS feed_url,
+    users.name AS user_name,
+    (
+        SELECT COUNT(*) FROM posts
+        WHERE posts.feed_id = feed_follows.feed_id
+        AND NOT EXISTS (
+            SELECT 1 FROM post_reads
+            WHERE post_reads.post_id = posts.id
+            AND post_reads.user_id = feed_follows.user_id
+        )
+    ) AS unread_count
+FROM feed_follows
+INNER JOIN feeds ON feeds.id = feed_follows.feed_id
+INNER JOIN users ON users.id = feed_follows.user_id
+WHERE feed_follows.user_id = sqlc.arg(user_id)
+AND (
+    sqlc.narg(tag)::text IS NULL
+    OR EXISTS (
+        SELECT 1 FROM feed_follow_tags
+        WHERE feed_follow_tags.feed_follow_id = feed_follows.id
+        AND feed_follow_tags.tag = sqlc.narg(tag)
+    )
+)
+ORDER BY feeds.name;
+
+-- name: DeleteFeedFollow :execrows
+DELETE FROM feed_follows
+USING feeds
+WHERE feed_follows.feed_id = feeds.id
+AND feed_follows.user_id = $1
+AND feeds.url = $2;
@@ -0,0 +1,88 @@
+-- name: CreateFeed :one
+INSERT INTO feeds(id, created_at, updated_at, name, url, user_id)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6
+)
+RETURNING *;
+
+-- name: GetFeedByURL :one
+SELECT * FROM feeds
+WHERE url = $1;
+
+-- name: GetNextFeedsToFetch :many
+SELECT * FROM feeds
+WHERE disabled_at IS NULL
+AND (next_fetch_at IS NULL OR next_fetch_at <= NOW())
+ORDER BY last_fetched_at ASC NULLS FIRST
+LIMIT $1;
+
+-- name: CountFeeds :one
+SELECT COUNT(*) FROM feeds;
+
+-- name: MarkFeedFetched :exec
+UPDATE feeds
+SET last_fetched_at = NOW(),
+    updated_at = NOW()
+WHERE id = $1;
+
+-- name: UpdateFeedCache :exec
+UPDATE feeds
+SET etag = $2,
+    last_modified = $3,
+    fetch_count = fetch_count + 1,
+    failure_count = 0,
+    last_error = NULL,
+    next_fetch_at = NULL,
+    updated_at = NOW()
+WHERE id = $1;
+
+-- name: RecordFeedCacheHit :exec
+UPDATE feeds
+SET fetch_count = fetch_count + 1,
+    cache_hits = cache_hits + 1,
+    failure_count = 0,
+    last_error = NULL,
+    next_fetch_at = NULL,
+    updated_at = NOW()
+WHERE id = $1;
+
+-- name: RecordFeedFailure :exec
+UPDATE feeds
+SET failure_count = sqlc.arg(failure_count),
+    last_error = sqlc.arg(last_error),
+    next_fetch_at = NOW() + sqlc.arg(backoff_seconds)::float8 * INTERVAL '1 second',
+    disabled_at = CASE WHEN sqlc.arg(disable)::boolean THEN NOW() ELSE NULL END,
+    updated_at = NOW()
+WHERE id = sqlc.arg(id);
+
+-- name: EnableFeed :execrows
+UPDATE feeds
+SET failure_count = 0,
+    last_error = NULL,
+    next_fetch_at = NULL,
+    disabled_at = NULL,
+    updated_at = NOW()
+WHERE url = $1;
+
+-- name: GetFeeds :many
+SELECT
+    feeds.*,
+    users.name AS user_name
+FROM feeds
+INNER JOIN users ON users.id = feeds.user_id
+ORDER BY feeds.name;
+
+-- name: GetBrokenFeeds :many
+SELECT
+    feeds.*,
+    users.name AS user_name
+FROM feeds
+INNER JOIN users ON users.id = feeds.user_id
+WHERE feeds.failure_count > 0
+OR feeds.disabled_at IS NOT NULL
+ORDER BY feeds.disabled_at ASC NULLS LAST, feeds.failure_count DESC;
@@ -0,0 +1,22 @@
+-- name: MarkPostRead :execrows
+INSERT INTO post_reads(user_id, post_id)
+VALUES (
+    $1,
+    $2
+)
+ON CONFLICT (user_id, post_id) DO NOTHING;
+
+-- name: MarkFeedPostsRead :execrows
+INSERT INTO post_reads(user_id, post_id)
+SELECT sqlc.arg(user_id)::uuid, posts.id
+FROM posts
+WHERE posts.feed_id = sqlc.arg(feed_id)
+ON CONFLICT (user_id, post_id) DO NOTHING;
+
+-- name: MarkAllPostsRead :execrows
+INSERT INTO post_reads(user_id, post_id)
+SELECT feed_follows.user_id, posts.id
+FROM posts
+INNER JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+WHERE feed_follows.user_id = $1
+ON CONFLICT (user_id, post_id) DO NOTHING;
@@ -0,0 +1,35 @@
+-- name: StarPost :one
+INSERT INTO post_stars(user_id, post_id, note, created_at)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4
+)
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET note = EXCLUDED.note
+RETURNING *;
+
+-- name: UnstarPost :execrows
+DELETE FROM post_stars
+WHERE user_id = $1
+AND post_id = $2;
+
+-- name: GetStarredPostsForUser :many
+SELECT
+    posts.id,
+    posts.created_at,
+    posts.updated_at,
+    posts.title,
+    posts.url,
+    posts.description,
+    posts.published_at,
+    posts.feed_id,
+    feeds.name AS feed_name,
+    post_stars.note,
+    post_stars.created_at AS starred_at
+FROM post_stars
+INNER JOIN posts ON posts.id = post_stars.post_id
+INNER JOIN feeds ON feeds.id = posts.feed_id
+WHERE post_stars.user_id = $1
+ORDER BY post_stars.created_at DESC;
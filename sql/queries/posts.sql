@@ -0,0 +1,181 @@
+-- name: CreatePost :exec
+INSERT INTO posts(id, created_at, updated_at, title, url, description, published_at, feed_id)
+SELECT
+    sqlc.arg(id)::uuid,
+    sqlc.arg(created_at)::timestamptz,
+    sqlc.arg(updated_at)::timestamptz,
+    sqlc.arg(title)::text,
+    sqlc.arg(url)::text,
+    sqlc.narg(description)::text,
+    sqlc.narg(published_at)::timestamptz,
+    sqlc.arg(feed_id)::uuid
+WHERE NOT EXISTS (
+    SELECT 1 FROM pruned_posts
+    WHERE pruned_posts.feed_id = sqlc.arg(feed_id)::uuid
+    AND pruned_posts.url = sqlc.arg(url)::text
+)
+ON CONFLICT (url) DO UPDATE
+SET updated_at = EXCLUDED.updated_at,
+    title = EXCLUDED.title,
+    description = EXCLUDED.description,
+    published_at = EXCLUDED.published_at;
+
+-- name: GetPost :one
+SELECT id, created_at, updated_at, title, url, description, published_at, feed_id FROM posts
+WHERE id = $1;
+
+-- name: GetPostsForUser :many
+SELECT
+    posts.id,
+    posts.created_at,
+    posts.updated_at,
+    posts.title,
+    posts.url,
+    posts.description,
+    posts.published_at,
+    posts.feed_id,
+    feeds.name AS feed_name,
+    EXISTS (
+        SELECT 1 FROM post_reads
+        WHERE post_reads.post_id = posts.id
+        AND post_reads.user_id = feed_follows.user_id
+    ) AS read
+FROM posts
+INNER JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+INNER JOIN feeds ON feeds.id = posts.feed_id
+WHERE feed_follows.user_id = sqlc.arg(user_id)
+AND (
+    NOT sqlc.arg(unread_only)::boolean
+    OR NOT EXISTS (
+        SELECT 1 FROM post_reads
+        WHERE post_reads.post_id = posts.id
+        AND post_reads.user_id = feed_follows.user_id
+    )
+)
+AND (
+    sqlc.narg(tag)::text IS NULL
+    OR EXISTS (
+        SELECT 1 FROM feed_follow_tags
+        WHERE feed_follow_tags.feed_follow_id = feed_follows.id
+        AND feed_follow_tags.tag = sqlc.narg(tag)
+    )
+)
+ORDER BY posts.published_at DESC NULLS LAST
+LIMIT sqlc.arg(max_posts);
+
+-- name: SearchPosts :many
+SELECT
+    posts.id,
+    posts.title,
+    posts.url,
+    posts.published_at,
+    feeds.name AS feed_name,
+    ts_rank(posts.search_vector, search_query) AS rank,
+    ts_headline(
+        'english',
+        regexp_replace(coalesce(posts.description, posts.title), '<[^>]*>', '', 'g'),
+        search_query,
+        'StartSel=**, StopSel=**, MaxFragments=2, MaxWords=25, MinWords=10, FragmentDelimiter=" ... "'
+    )::text AS snippet
+FROM posts
+INNER JOIN feeds ON feeds.id = posts.feed_id
+INNER JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+CROSS JOIN websearch_to_tsquery('english', sqlc.arg(query)) AS search_query
+WHERE feed_follows.user_id = sqlc.arg(user_id)
+AND posts.search_vector @@ search_query
+AND (sqlc.narg(feed_name)::text IS NULL OR feeds.name = sqlc.narg(feed_name))
+AND (sqlc.narg(since)::timestamptz IS NULL OR posts.published_at >= sqlc.narg(since))
+ORDER BY rank DESC, posts.published_at DESC NULLS LAST
+LIMIT sqlc.arg(max_results);
+
+-- name: CountPrunablePosts :one
+-- Posts matching the retention policy: older than older_than or beyond
+-- the newest max_per_feed in their feed (either may be NULL to disable
+-- it). Starred posts are always kept, and with keep_unread so are posts
+-- someone following the feed hasn't read yet.
+WITH ranked AS (
+    SELECT
+        posts.id,
+        posts.feed_id,
+        COALESCE(posts.published_at, posts.created_at) AS posted_at,
+        ROW_NUMBER() OVER (
+            PARTITION BY posts.feed_id
+            ORDER BY COALESCE(posts.published_at, posts.created_at) DESC
+        ) AS feed_rank
+    FROM posts
+)
+SELECT COUNT(*) FROM ranked
+WHERE (
+    ranked.posted_at < sqlc.narg(older_than)::timestamptz
+    OR ranked.feed_rank > sqlc.narg(max_per_feed)::bigint
+)
+AND NOT EXISTS (
+    SELECT 1 FROM post_stars
+    WHERE post_stars.post_id = ranked.id
+)
+AND (
+    NOT sqlc.arg(keep_unread)::boolean
+    OR NOT EXISTS (
+        SELECT 1 FROM feed_follows
+        WHERE feed_follows.feed_id = ranked.feed_id
+        AND NOT EXISTS (
+            SELECT 1 FROM post_reads
+            WHERE post_reads.post_id = ranked.id
+            AND post_reads.user_id = feed_follows.user_id
+        )
+    )
+);
+
+-- name: PrunePosts :execrows
+-- Same policy as CountPrunablePosts. Deleted urls are recorded in
+-- pruned_posts so CreatePost won't add them back.
+WITH ranked AS (
+    SELECT
+        posts.id,
+        posts.feed_id,
+        COALESCE(posts.published_at, posts.created_at) AS posted_at,
+        ROW_NUMBER() OVER (
+            PARTITION BY posts.feed_id
+            ORDER BY COALESCE(posts.published_at, posts.created_at) DESC
+        ) AS feed_rank
+    FROM posts
+),
+deleted AS (
+    DELETE FROM posts
+    USING ranked
+    WHERE posts.id = ranked.id
+    AND (
+        ranked.posted_at < sqlc.narg(older_than)::timestamptz
+        OR ranked.feed_rank > sqlc.narg(max_per_feed)::bigint
+    )
+    AND NOT EXISTS (
+        SELECT 1 FROM post_stars
+        WHERE post_stars.post_id = ranked.id
+    )
+    AND (
+        NOT sqlc.arg(keep_unread)::boolean
+        OR NOT EXISTS (
+            SELECT 1 FROM feed_follows
+            WHERE feed_follows.feed_id = ranked.feed_id
+            AND NOT EXISTS (
+                SELECT 1 FROM post_reads
+                WHERE post_reads.post_id = ranked.id
+                AND post_reads.user_id = feed_follows.user_id
+            )
+        )
+    )
+    RETURNING posts.feed_id, posts.url
+)
+INSERT INTO pruned_posts(feed_id, url, pruned_at)
+SELECT deleted.feed_id, deleted.url, NOW() FROM deleted
+ON CONFLICT (feed_id, url) DO NOTHING;
+
+-- name: ExpirePrunedPosts :execrows
+-- Forgets urls pruned before pruned_before, so pruned_posts doesn't grow
+-- forever. If a post is still in its feed agg stores it again, and the
+-- next prune removes it.
+DELETE FROM pruned_posts
+WHERE pruned_at < sqlc.arg(pruned_before)::timestamptz;
+
+-- name: DeletePrunedPosts :exec
+DELETE FROM pruned_posts;
@@ -0,0 +1,13 @@
+-- +goose Up
+ALTER TABLE feeds
+    ADD COLUMN etag TEXT,
+    ADD COLUMN last_modified TEXT,
+    ADD COLUMN fetch_count INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN cache_hits INTEGER NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE feeds
+    DROP COLUMN etag,
+    DROP COLUMN last_modified,
+    DROP COLUMN fetch_count,
+    DROP COLUMN cache_hits;
@@ -0,0 +1,13 @@
+-- +goose Up
+ALTER TABLE feeds
+    ADD COLUMN failure_count INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN last_error TEXT,
+    ADD COLUMN next_fetch_at TIMESTAMPTZ,
+    ADD COLUMN disabled_at TIMESTAMPTZ;
+
+-- +goose Down
+ALTER TABLE feeds
+    DROP COLUMN failure_count,
+    DROP COLUMN last_error,
+    DROP COLUMN next_fetch_at,
+    DROP COLUMN disabled_at;
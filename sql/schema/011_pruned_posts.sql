@@ -0,0 +1,12 @@
+-- +goose Up
+-- Urls of posts removed by prune, so agg doesn't store them again while
+-- they are still listed in their feed.
+CREATE TABLE pruned_posts (
+    feed_id UUID NOT NULL REFERENCES feeds(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    pruned_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (feed_id, url)
+);
+
+-- +goose Down
+DROP TABLE pruned_posts;
@@ -0,0 +1,10 @@
+-- +goose Up
+CREATE TABLE feed_follow_tags (
+    feed_follow_id UUID NOT NULL REFERENCES feed_follows(id) ON DELETE CASCADE,
+    tag TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (feed_follow_id, tag)
+);
+
+-- +goose Down
+DROP TABLE feed_follow_tags;